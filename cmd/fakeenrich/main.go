@@ -0,0 +1,77 @@
+// Command fakeenrich serves deterministic fakes of the agify, genderize and
+// nationalize APIs so the service can run without internet access.
+package main
+
+import (
+	"Effective/pkg/fakeenrich"
+	"Effective/pkg/logger"
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	baseURL := flag.String("base-url", "http://localhost:9000", "base URL printed for AGIFY_URL, GENDERIZE_URL and NATIONALIZE_URL")
+	latency := flag.Duration("latency", 0, "delay added to every response")
+	rateLimitRate := flag.Float64("rate-limit-rate", 0, "fraction of requests answered with 429")
+	errorRate := flag.Float64("error-rate", 0, "fraction of requests answered with 500")
+	malformedRate := flag.Float64("malformed-rate", 0, "fraction of requests answered with malformed JSON")
+	retryAfter := flag.Duration("retry-after", 0, "Retry-After sent with injected 429 and 500 responses")
+	quota := flag.Int("quota", 0, "requests allowed per API and quota window, 0 for unlimited")
+	quotaWindow := flag.Duration("quota-window", 24*time.Hour, "quota reset interval")
+	seed := flag.Uint64("seed", 1, "seed of the injected fault sequence")
+	flag.Parse()
+
+	logger, err := logger.NewLogger()
+	if err != nil {
+		log.Fatalf("init logger: %v", err)
+	}
+	defer func() { _ = logger.Sync() }()
+
+	fake := fakeenrich.New(fakeenrich.Config{
+		Faults: fakeenrich.Faults{
+			Latency:       *latency,
+			RateLimitRate: *rateLimitRate,
+			ErrorRate:     *errorRate,
+			MalformedRate: *malformedRate,
+			RetryAfter:    *retryAfter,
+		},
+		Quota:       *quota,
+		QuotaWindow: *quotaWindow,
+		Seed:        *seed,
+	})
+
+	srv := &http.Server{Addr: *addr, Handler: fake}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("Listen:", zap.Error(err))
+		}
+	}()
+
+	urls := fakeenrich.URLsFor(*baseURL)
+	logger.Info("Fake enrichment APIs started",
+		zap.String("addr", *addr),
+		zap.String("AGIFY_URL", urls.Agify),
+		zap.String("GENDERIZE_URL", urls.Genderize),
+		zap.String("NATIONALIZE_URL", urls.Nationalize),
+	)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Error("Shutdown failed", zap.Error(err))
+	}
+}
@@ -2,6 +2,9 @@
 // @version 1.0
 // @host localhost:8080
 // @BasePath /api/v1
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name X-Admin-Token
 
 package main
 
@@ -59,25 +62,75 @@ func run() error {
 	}
 
 	enrich := service.NewEnricher(logger, cfg)
+	providers, err := service.NewProviderChain(cfg, enrich, logger)
+	if err != nil {
+		logger.Fatal("Failed to configure enrichment providers", zap.Error(err))
+	}
+	cacheRepo := repository.NewEnrichmentCacheRepository(conn)
+	cachedEnrich := service.NewCachedEnricher(providers, providers.IsRemote, cacheRepo, logger, cfg.Cache.Size, cfg.Cache.TTL)
 	repo := repository.NewPersonRepository(conn)
-	service := service.NewPersonService(repo, logger, enrich)
-	h := handler.NewPersonHandler(service, logger)
+	jobRepo := repository.NewEnrichmentJobRepository(conn)
+	personService := service.NewPersonService(repo, jobRepo, logger, cachedEnrich, cfg)
+	worker := service.NewEnrichmentWorker(jobRepo, personService, logger, cfg.Async)
+	purger := service.NewPurger(personService, logger, cfg.Trash)
+	h := handler.NewPersonHandler(personService, logger)
+	cacheHandler := handler.NewCacheHandler(cachedEnrich, logger)
+	enrichmentHandler := handler.NewEnrichmentHandler(enrich, providers, logger)
+
+	if err := handler.RegisterValidators(); err != nil {
+		logger.Fatal("Failed to register validators", zap.Error(err))
+	}
 
 	router := gin.New()
-	router.Use(gin.Recovery(), gin.Logger())
+	router.Use(gin.Recovery(), gin.Logger(), handler.RequestID(), handler.Actor(), handler.Admin(cfg.HTTP.AdminToken))
 	router.GET("/ping", func(c *gin.Context) {
 		c.String(200, "pong")
 	})
 	v1 := router.Group("/api/v1")
 	{
 		v1.POST("/person", h.CreatePerson)
+		v1.POST("/person/parse", h.ParseName)
+		v1.GET("/person/:id", h.GetPerson)
 		v1.DELETE("/person/:id", h.DeletePerson)
 		v1.PATCH("/person/:id", h.UpdatePerson)
+		v1.PUT("/person/:id", h.ReplacePerson)
 		v1.GET("/persons", h.GetPersons)
+		v1.GET("/persons/export", h.ExportPersons)
+		v1.GET("/persons/trash", handler.RequireAdmin(), h.GetTrash)
+		v1.POST("/person/:id/restore", h.RestorePerson)
+		v1.GET("/person/:id/history", h.GetHistory)
+		v1.POST("/person/:id/revert", h.RevertPerson)
+		v1.POST("/person/:id/enrich", h.EnrichPerson)
+		v1.POST("/persons/enrich", h.EnrichPersons)
+		v1.GET("/operations/:id", h.GetEnrichmentOperation)
+	}
+	admin := v1.Group("/admin", handler.RequireAdmin())
+	{
+		admin.GET("/enrichment-cache", cacheHandler.GetCacheStats)
+		admin.DELETE("/enrichment-cache", cacheHandler.InvalidateAll)
+		admin.DELETE("/enrichment-cache/:name", cacheHandler.InvalidateName)
+		admin.GET("/enrichment/status", enrichmentHandler.GetStatus)
+		admin.POST("/enrichment/datasets/reload", enrichmentHandler.ReloadDatasets)
+		admin.POST("/persons/purge", h.PurgeTrash)
 	}
 
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	defer stopWorker()
+	worker.Start(workerCtx)
+	purger.Start(workerCtx)
+
 	srv := server.NewServer(cfg, logger, router)
 	srv.Run()
 
+	stopWorker()
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancelDrain()
+	if err := worker.Wait(drainCtx); err != nil {
+		logger.Error("Enrichment workers did not stop in time", zap.Error(err))
+	}
+	if err := purger.Wait(drainCtx); err != nil {
+		logger.Error("Trash purger did not stop in time", zap.Error(err))
+	}
+
 	return nil
 }
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"Effective/pkg/cassette"
+	"Effective/pkg/names"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -13,9 +16,14 @@ const (
 )
 
 type Config struct {
-	HTTP     *HTTPServer
-	Postgres *PostgresConfig
-	APIUrl   *APIUrl
+	HTTP      *HTTPServer
+	Postgres  *PostgresConfig
+	APIUrl    *APIUrl
+	Cache     *EnrichmentCache
+	Enricher  *Enricher
+	Providers *Providers
+	Async     *AsyncEnrichment
+	Trash     *Trash
 }
 
 type HTTPServer struct {
@@ -23,6 +31,10 @@ type HTTPServer struct {
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
+
+	// AdminToken is the X-Admin-Token value that opens the admin routes, the
+	// trash and include_deleted. They are closed to everyone when it is empty.
+	AdminToken string
 }
 
 type PostgresConfig struct {
@@ -40,6 +52,94 @@ type APIUrl struct {
 	NationalizeUrl string
 }
 
+type EnrichmentCache struct {
+	Size int
+	TTL  time.Duration
+}
+
+type Enricher struct {
+	Timeout     time.Duration
+	MaxRetries  int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+
+	BreakerFailureThreshold int
+	BreakerOpenTimeout      time.Duration
+	BreakerHalfOpenRequests int
+
+	DefaultCountry string
+	TwoStepCountry bool
+
+	FailurePolicy string
+
+	// Transliteration is the scheme, icao or gost, used to spell non-Latin
+	// names for the enrichment APIs.
+	Transliteration string
+
+	// HTTPMode is passthrough, record or replay; recorded responses are
+	// stored in CassetteDir.
+	HTTPMode    string
+	CassetteDir string
+}
+
+// Policies for a person whose enrichment partly failed: strict rejects the
+// write, lenient saves what was enriched and deferred also queues a retry of
+// the failed fields.
+const (
+	FailureStrict   = "strict"
+	FailureLenient  = "lenient"
+	FailureDeferred = "deferred"
+)
+
+// Trash configures how long soft-deleted persons are kept. A zero Retention
+// keeps them forever; otherwise they are purged every PurgeInterval.
+type Trash struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// AsyncEnrichment configures the enrichment job queue and its worker pool.
+type AsyncEnrichment struct {
+	Enabled      bool
+	Workers      int
+	PollInterval time.Duration
+	MaxAttempts  int
+	RetryDelay   time.Duration
+	JobLease     time.Duration
+	JobTimeout   time.Duration
+
+	// BatchSize is how many jobs a worker claims at once; their names are
+	// looked up with one batch request per attribute. The jobs then run one
+	// after another, so Load requires JobLease to cover the prefetch and
+	// every job of the batch, each bounded by JobTimeout.
+	BatchSize int
+}
+
+const (
+	ProviderHTTP    = "http"
+	ProviderStatic  = "static"
+	ProviderDataset = "dataset"
+)
+
+// Providers holds the ordered fallback chain of enrichment providers for
+// each attribute.
+type Providers struct {
+	Age         []Provider
+	Gender      []Provider
+	Nationality []Provider
+}
+
+type Provider struct {
+	Name           string
+	Type           string
+	URL            string
+	MinProbability float64
+	MinCount       int
+	Value          string
+	Probability    float64
+	Path           string
+}
+
 func Load() (*Config, error) {
 	viper.SetConfigFile(pathConfigFile)
 	viper.SetConfigType(dotenv)
@@ -55,6 +155,7 @@ func Load() (*Config, error) {
 			ReadTimeout:     viper.GetDuration("HTTP_READ_TIMEOUT"),
 			WriteTimeout:    viper.GetDuration("HTTP_WRITE_TIMEOUT"),
 			ShutdownTimeout: viper.GetDuration("HTTP_SHUTDOWN_TIMEOUT"),
+			AdminToken:      viper.GetString("HTTP_ADMIN_TOKEN"),
 		},
 		Postgres: &PostgresConfig{
 			Host:     viper.GetString("POSTGRES_HOST"),
@@ -69,10 +170,137 @@ func Load() (*Config, error) {
 			GenderizeUrl:   viper.GetString("GENDERIZE_URL"),
 			NationalizeUrl: viper.GetString("NATIONALIZE_URL"),
 		},
+		Cache: &EnrichmentCache{
+			Size: viper.GetInt("ENRICH_CACHE_SIZE"),
+			TTL:  viper.GetDuration("ENRICH_CACHE_TTL"),
+		},
+		Enricher: &Enricher{
+			Timeout:     viper.GetDuration("ENRICH_HTTP_TIMEOUT"),
+			MaxRetries:  viper.GetInt("ENRICH_MAX_RETRIES"),
+			BackoffBase: viper.GetDuration("ENRICH_BACKOFF_BASE"),
+			BackoffMax:  viper.GetDuration("ENRICH_BACKOFF_MAX"),
+
+			BreakerFailureThreshold: viper.GetInt("ENRICH_BREAKER_FAILURE_THRESHOLD"),
+			BreakerOpenTimeout:      viper.GetDuration("ENRICH_BREAKER_OPEN_TIMEOUT"),
+			BreakerHalfOpenRequests: viper.GetInt("ENRICH_BREAKER_HALF_OPEN_REQUESTS"),
+
+			DefaultCountry: strings.ToUpper(viper.GetString("ENRICH_DEFAULT_COUNTRY")),
+			TwoStepCountry: viper.GetBool("ENRICH_TWO_STEP_COUNTRY"),
+
+			FailurePolicy: strings.ToLower(viper.GetString("ENRICH_FAILURE_POLICY")),
+
+			Transliteration: strings.ToLower(viper.GetString("ENRICH_TRANSLITERATION")),
+
+			HTTPMode:    strings.ToLower(viper.GetString("ENRICH_HTTP_MODE")),
+			CassetteDir: viper.GetString("ENRICH_CASSETTE_DIR"),
+		},
+		Providers: &Providers{
+			Age:         loadProviders("ENRICH_AGE_PROVIDERS", "agify", viper.GetString("AGIFY_URL")),
+			Gender:      loadProviders("ENRICH_GENDER_PROVIDERS", "genderize", viper.GetString("GENDERIZE_URL")),
+			Nationality: loadProviders("ENRICH_NATIONALITY_PROVIDERS", "nationalize", viper.GetString("NATIONALIZE_URL")),
+		},
+		Async: &AsyncEnrichment{
+			Enabled:      viper.GetBool("ENRICH_ASYNC"),
+			Workers:      viper.GetInt("ENRICH_WORKERS"),
+			PollInterval: viper.GetDuration("ENRICH_POLL_INTERVAL"),
+			MaxAttempts:  viper.GetInt("ENRICH_JOB_MAX_ATTEMPTS"),
+			RetryDelay:   viper.GetDuration("ENRICH_JOB_RETRY_DELAY"),
+			JobLease:     viper.GetDuration("ENRICH_JOB_LEASE"),
+			JobTimeout:   viper.GetDuration("ENRICH_JOB_TIMEOUT"),
+			BatchSize:    viper.GetInt("ENRICH_JOB_BATCH_SIZE"),
+		},
+		Trash: &Trash{
+			Retention:     viper.GetDuration("TRASH_RETENTION"),
+			PurgeInterval: viper.GetDuration("TRASH_PURGE_INTERVAL"),
+		},
+	}
+
+	switch cfg.Enricher.FailurePolicy {
+	case "":
+		cfg.Enricher.FailurePolicy = FailureLenient
+	case FailureStrict, FailureLenient, FailureDeferred:
+	default:
+		return nil, fmt.Errorf("unknown enrichment failure policy %q", cfg.Enricher.FailurePolicy)
+	}
+
+	switch cfg.Enricher.HTTPMode {
+	case "":
+		cfg.Enricher.HTTPMode = cassette.ModePassthrough
+	case cassette.ModePassthrough, cassette.ModeRecord, cassette.ModeReplay:
+	default:
+		return nil, fmt.Errorf("unknown enrichment HTTP mode %q", cfg.Enricher.HTTPMode)
+	}
+
+	switch cfg.Enricher.Transliteration {
+	case "":
+		cfg.Enricher.Transliteration = names.SchemeICAO
+	case names.SchemeICAO, names.SchemeGOST:
+	default:
+		return nil, fmt.Errorf("unknown transliteration scheme %q", cfg.Enricher.Transliteration)
+	}
+
+	if cfg.Async.Enabled {
+		if lease := cfg.Async.minJobLease(); cfg.Async.JobLease < lease {
+			return nil, fmt.Errorf("enrichment job lease %s is shorter than %s needed for a batch of %d jobs", cfg.Async.JobLease, lease, max(cfg.Async.BatchSize, 1))
+		}
 	}
 	return cfg, nil
 }
 
+// minJobLease is the longest a claimed batch can take: the prefetch of its
+// names, when it has more than one job, and then each job in turn. A shorter
+// lease lets another worker reclaim jobs that are still being processed.
+func (a *AsyncEnrichment) minJobLease() time.Duration {
+	batch := max(a.BatchSize, 1)
+	if batch == 1 {
+		return a.JobTimeout
+	}
+	return time.Duration(batch+1) * a.JobTimeout
+}
+
+// loadProviders reads the comma-separated provider list under key. A provider
+// NAME is configured by ENRICH_PROVIDER_<NAME>_TYPE, _URL, _PATH,
+// _MIN_PROBABILITY, _MIN_COUNT, _VALUE and _PROBABILITY. The built-in public
+// API defaults to an http provider at builtinURL and is used alone when the
+// list is empty.
+func loadProviders(key, builtin, builtinURL string) []Provider {
+	envName := strings.NewReplacer("-", "_", ".", "_")
+	providers := make([]Provider, 0)
+
+	for _, name := range strings.Split(viper.GetString(key), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		prefix := "ENRICH_PROVIDER_" + strings.ToUpper(envName.Replace(name)) + "_"
+		p := Provider{
+			Name:           name,
+			Type:           viper.GetString(prefix + "TYPE"),
+			URL:            viper.GetString(prefix + "URL"),
+			MinProbability: viper.GetFloat64(prefix + "MIN_PROBABILITY"),
+			MinCount:       viper.GetInt(prefix + "MIN_COUNT"),
+			Value:          viper.GetString(prefix + "VALUE"),
+			Probability:    viper.GetFloat64(prefix + "PROBABILITY"),
+			Path:           viper.GetString(prefix + "PATH"),
+		}
+		if name == builtin {
+			if p.Type == "" {
+				p.Type = ProviderHTTP
+			}
+			if p.URL == "" {
+				p.URL = builtinURL
+			}
+		}
+		providers = append(providers, p)
+	}
+
+	if len(providers) == 0 {
+		providers = append(providers, Provider{Name: builtin, Type: ProviderHTTP, URL: builtinURL})
+	}
+	return providers
+}
+
 func (p PostgresConfig) ToDSN() string {
 	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
 		p.User, p.Password, p.Host, p.Port, p.DBName, p.SSLMode)
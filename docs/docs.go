@@ -15,35 +15,217 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/person": {
-            "post": {
-                "description": "Create a new person with the provided details",
-                "consumes": [
+        "/admin/enrichment-cache": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Hit and miss counters of the enrichment cache",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get enrichment cache statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.CacheStats"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Remove every cached enrichment value",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Person"
+                    "Admin"
                 ],
-                "summary": "Create a new person",
+                "summary": "Invalidate the whole enrichment cache",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.InvalidateCacheResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/enrichment-cache/{name}": {
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Remove every cached enrichment value for the given name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Invalidate cached enrichment for a name",
                 "parameters": [
                     {
-                        "description": "Person details",
-                        "name": "person",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "Name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.CreatePersonRequest"
+                            "$ref": "#/definitions/handler.InvalidateCacheResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/enrichment/datasets/reload": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
                     }
                 ],
+                "description": "Re-read every local name dataset used by the offline provider",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Reload offline enrichment datasets",
                 "responses": {
                     "200": {
-                        "description": "ID of the created person",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.DatasetStatus"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/enrichment/status": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Provider chains and circuit breaker state of every enrichment provider",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get enrichment provider status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.EnrichmentStatusResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/persons/purge": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Permanently delete the persons that have been in the trash for longer than older_than, or the configured retention",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Purge the trash",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Minimum time in the trash, such as 720h",
+                        "name": "older_than",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PurgeResponse"
                         }
                     },
                     "400": {
@@ -52,6 +234,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -61,17 +249,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/person/{id}": {
-            "delete": {
-                "description": "Delete a person by ID",
+        "/operations/{id}": {
+            "get": {
+                "description": "Progress of a bulk re-enrichment",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Person"
                 ],
-                "summary": "Delete a person",
+                "summary": "Get a re-enrichment operation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Person ID",
+                        "description": "Operation ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -79,9 +270,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully deleted",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.EnrichmentOperationResponse"
                         }
                     },
                     "400": {
@@ -90,6 +281,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -97,9 +294,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Update a person by ID",
+            }
+        },
+        "/person": {
+            "post": {
+                "description": "Create a new person with the provided details. In async mode the person is enriched in the background and 202 is returned. Fields that could not be enriched are listed in unenriched_fields; under the strict failure policy the person is not created and 502 is returned",
                 "consumes": [
                     "application/json"
                 ],
@@ -109,28 +308,30 @@ const docTemplate = `{
                 "tags": [
                     "Person"
                 ],
-                "summary": "Update a person",
+                "summary": "Create a new person",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Person ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Person details to update",
+                        "description": "Person details, with either name and surname or full_name",
                         "name": "person",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UpdatePersonRequest"
+                            "$ref": "#/definitions/dto.CreatePersonRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "Created person",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreatePersonResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Person accepted for enrichment",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreatePersonResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -138,18 +339,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrResponse"
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ValidationErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrResponse"
                         }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/handler.EnrichmentErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/persons": {
-            "get": {
-                "description": "Retrieve a paginated list of persons",
+        "/person/parse": {
+            "post": {
+                "description": "Split a free-form full name such as \"Ivanov Ivan Ivanovich\" or \"Ivan I. Ivanov\" into name, surname and patronymic. The order is detected from patronymic and surname suffixes and name dictionaries, with a confidence between 0 and 1",
                 "consumes": [
                     "application/json"
                 ],
@@ -159,31 +372,23 @@ const docTemplate = `{
                 "tags": [
                     "Person"
                 ],
-                "summary": "Get a list of persons",
+                "summary": "Split a full name",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number (default: 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Page size (default: 10)",
-                        "name": "size",
-                        "in": "query"
+                        "description": "Full name",
+                        "name": "name",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ParseNameRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.PersonResponse"
-                            }
+                            "$ref": "#/definitions/dto.ParsedNameResponse"
                         }
                     },
                     "400": {
@@ -191,66 +396,1364 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/handler.ErrResponse"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrResponse"
-                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.CreatePersonRequest": {
-            "type": "object",
-            "required": [
-                "name",
-                "surname"
-            ],
-            "properties": {
-                "name": {
-                    "type": "string",
-                    "maxLength": 50,
-                    "minLength": 2
-                },
-                "surname": {
-                    "type": "string",
-                    "maxLength": 50,
-                    "minLength": 2
-                }
-            }
         },
-        "dto.PersonResponse": {
-            "type": "object",
-            "properties": {
-                "age": {
-                    "type": "integer"
-                },
+        "/person/{id}": {
+            "get": {
+                "description": "Get a person by ID. Its nationality candidates and provenance are returned when named in include. With as_of the person is returned as it was at that time, without provenance. The response carries ETag and Last-Modified headers; a matching If-None-Match or an If-Modified-Since at or after the last change is answered with 304",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Person"
+                ],
+                "summary": "Get a person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also return a person in the trash; needs the admin token",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time to read the person at",
+                        "name": "as_of",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional parts to include: nationalities, provenance",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date of a cached copy",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PersonResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace every editable field of a person. Fields left out are cleared; name and surname are required. Changed fields are recorded as manual values and locked against re-enrichment. With If-Match the person must still carry that ETag, or 412 is returned with its current representation",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Person"
+                ],
+                "summary": "Replace a person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Caller the change is attributed to",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the person must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Person",
+                        "name": "person",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PersonDocument"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PersonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Current person",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PersonResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ValidationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a person by ID. With If-Match the person must still carry that ETag, or 412 is returned with its current representation",
+                "tags": [
+                    "Person"
+                ],
+                "summary": "Delete a person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the person must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Current person",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PersonResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update a person by ID. Changed fields are recorded as manual values and locked against re-enrichment. A plain JSON body only sets its non-empty fields; an application/merge-patch+json body (RFC 7396) also clears fields set to null, and an application/json-patch+json body (RFC 6902) applies its operations in order, answering a failed test with 409. With If-Match the person must still carry that ETag, or 412 is returned with its current representation; the new ETag is returned in the ETag header",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Person"
+                ],
+                "summary": "Update a person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Caller the change is attributed to",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the person must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Person details to update",
+                        "name": "person",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdatePersonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Current person",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PersonResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ValidationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/person/{id}/enrich": {
+            "post": {
+                "description": "Re-run enrichment for one person and save the fields that could be enriched, following the failure policy. Fields locked by a manual change are skipped unless force is set",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Person"
+                ],
+                "summary": "Re-enrich a person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also enrich fields locked by a manual change",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional parts to include: provenance",
+                        "name": "include",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PersonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/handler.EnrichmentErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/person/{id}/history": {
+            "get": {
+                "description": "Get every change made to a person, newest first, with the actor, the request id and the fields it changed. Deleted persons keep their history",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Person"
+                ],
+                "summary": "Get the history of a person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PersonHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/person/{id}/restore": {
+            "post": {
+                "description": "Take a person out of the trash",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Restore a deleted person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PersonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Person is not in the trash",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/person/{id}/revert": {
+            "post": {
+                "description": "Set the names and attributes of a person back to the values it had at version and save them as a new change. Reverted names are locked as manual values; reverted attributes keep the source they had. With If-Match the person must still carry that ETag, or 412 is returned with its current representation",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Person"
+                ],
+                "summary": "Revert a person to an earlier version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to revert to",
+                        "name": "version",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Caller the change is attributed to",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the person must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PersonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Current person",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PersonResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "The version breaks the current rules",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ValidationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/persons": {
+            "get": {
+                "description": "Retrieve a paginated list of persons. With as_of the persons are listed as they were at that time; provenance is then not available",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Person"
+                ],
+                "summary": "Get a list of persons",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size (default: 10)",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum number of samples behind the age",
+                        "name": "min_age_count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum gender probability (0-1)",
+                        "name": "min_gender_probability",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum number of samples behind the gender",
+                        "name": "min_gender_count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum nationality probability (0-1)",
+                        "name": "min_nationality_probability",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Country any nationality candidate must match (ISO 3166-1 alpha-2)",
+                        "name": "candidate_country",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum probability of the candidate country (0-1)",
+                        "name": "min_candidate_probability",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Enrichment status: pending, completed, partial or failed",
+                        "name": "enrichment_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only persons not enriched since this time (RFC 3339)",
+                        "name": "stale_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only persons missing any of these fields: age, gender, nationality",
+                        "name": "missing_fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional parts to include: nationalities, provenance",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Patronymic",
+                        "name": "patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort fields, descending with a - prefix: name, surname, patronymic, age, gender, nationality, created_at, updated_at, deleted_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also list persons in the trash; needs the admin token",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time to list the persons at",
+                        "name": "as_of",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PersonResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/persons/enrich": {
+            "post": {
+                "description": "Queue re-enrichment of every person matching the filter of GET /persons and return the operation tracking it. Pagination is ignored and fields locked by a manual change are skipped unless force is set",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Person"
+                ],
+                "summary": "Re-enrich persons in bulk",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Also enrich fields locked by a manual change",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum number of samples behind the age",
+                        "name": "min_age_count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum gender probability (0-1)",
+                        "name": "min_gender_probability",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum number of samples behind the gender",
+                        "name": "min_gender_count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum nationality probability (0-1)",
+                        "name": "min_nationality_probability",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Country any nationality candidate must match (ISO 3166-1 alpha-2)",
+                        "name": "candidate_country",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum probability of the candidate country (0-1)",
+                        "name": "min_candidate_probability",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Enrichment status: pending, completed, partial or failed",
+                        "name": "enrichment_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only persons not enriched since this time (RFC 3339)",
+                        "name": "stale_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only persons missing any of these fields: age, gender, nationality",
+                        "name": "missing_fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EnrichmentOperationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/persons/export": {
+            "get": {
+                "description": "Export every person matching the filter of GET /persons as CSV. Pagination is ignored",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "Person"
+                ],
+                "summary": "Export persons as CSV",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Surname",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Patronymic",
+                        "name": "patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Gender",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nationality",
+                        "name": "nationality",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort fields, descending with a - prefix",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time to export the persons at",
+                        "name": "as_of",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also export persons in the trash; needs the admin token",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/persons/trash": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "List the persons in the trash, most recently deleted first. Takes the filters of GET /persons",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "List deleted persons",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size (default: 10)",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Surname",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort fields, descending with a - prefix",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PersonResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.CreatePersonRequest": {
+            "type": "object",
+            "properties": {
+                "country_id": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string",
+                    "maxLength": 150
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2
+                },
+                "patronymic": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2
+                },
+                "surname": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2
+                }
+            }
+        },
+        "dto.CreatePersonResponse": {
+            "type": "object",
+            "properties": {
+                "enrichment_errors": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "enrichment_status": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name_confidence": {
+                    "type": "number"
+                },
+                "unenriched_fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.EnrichmentOperationResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "done": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "progress": {
+                    "type": "number"
+                },
+                "running": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.EnrichmentResponse": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/dto.FieldEnrichmentResponse"
+            }
+        },
+        "dto.FieldDiffResponse": {
+            "type": "object",
+            "properties": {
+                "after": {},
+                "before": {},
+                "field": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.FieldEnrichmentResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.FieldProvenanceResponse": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "locked": {
+                    "type": "boolean"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.NationalityResponse": {
+            "type": "object",
+            "properties": {
+                "country_id": {
+                    "type": "string"
+                },
+                "probability": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.ParseNameRequest": {
+            "type": "object",
+            "required": [
+                "full_name"
+            ],
+            "properties": {
+                "full_name": {
+                    "type": "string",
+                    "maxLength": 150
+                }
+            }
+        },
+        "dto.ParsedNameResponse": {
+            "type": "object",
+            "properties": {
+                "confidence": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "order": {
+                    "type": "string"
+                },
+                "patronymic": {
+                    "type": "string"
+                },
+                "surname": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PersonChangeResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "diff": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldDiffResponse"
+                    }
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PersonDocument": {
+            "type": "object",
+            "required": [
+                "name",
+                "surname"
+            ],
+            "properties": {
+                "age": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2
+                },
+                "nationality": {
+                    "type": "string"
+                },
+                "patronymic": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2
+                },
+                "surname": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2
+                }
+            }
+        },
+        "dto.PersonHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PersonChangeResponse"
+                    }
+                },
+                "person_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PersonResponse": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "type": "integer"
+                },
+                "age_count": {
+                    "type": "integer"
+                },
+                "age_source": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "deleted_at": {
                     "type": "string"
                 },
+                "enriched_at": {
+                    "type": "string"
+                },
+                "enrichment": {
+                    "$ref": "#/definitions/dto.EnrichmentResponse"
+                },
+                "enrichment_status": {
+                    "type": "string"
+                },
                 "gender": {
                     "type": "string"
                 },
+                "gender_count": {
+                    "type": "integer"
+                },
+                "gender_probability": {
+                    "type": "number"
+                },
+                "gender_source": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
                 "name": {
                     "type": "string"
                 },
+                "name_latin": {
+                    "type": "string"
+                },
+                "nationalities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NationalityResponse"
+                    }
+                },
                 "nationality": {
                     "type": "string"
                 },
+                "nationality_probability": {
+                    "type": "number"
+                },
+                "nationality_source": {
+                    "type": "string"
+                },
+                "patronymic": {
+                    "type": "string"
+                },
+                "patronymic_latin": {
+                    "type": "string"
+                },
+                "provenance": {
+                    "$ref": "#/definitions/dto.ProvenanceResponse"
+                },
                 "surname": {
                     "type": "string"
                 },
+                "surname_latin": {
+                    "type": "string"
+                },
+                "unenriched_fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "updated_at": {
                     "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ProvenanceResponse": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/dto.FieldProvenanceResponse"
+            }
+        },
+        "dto.PurgeResponse": {
+            "type": "object",
+            "properties": {
+                "purged": {
+                    "type": "integer"
                 }
             }
         },
@@ -264,13 +1767,50 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2
                 },
                 "nationality": {
                     "type": "string"
                 },
+                "patronymic": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2
+                },
                 "surname": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2
+                }
+            }
+        },
+        "handler.EnrichmentErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
                     "type": "string"
+                },
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handler.EnrichmentStatusResponse": {
+            "type": "object",
+            "properties": {
+                "breakers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.BreakerStatus"
+                    }
+                },
+                "providers": {
+                    "$ref": "#/definitions/service.ProviderChainStatus"
                 }
             }
         },
@@ -281,6 +1821,145 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "handler.FieldErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.InvalidateCacheResponse": {
+            "type": "object",
+            "properties": {
+                "removed": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.ValidationErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.FieldErrorResponse"
+                    }
+                }
+            }
+        },
+        "service.BreakerState": {
+            "type": "string",
+            "enum": [
+                "closed",
+                "open",
+                "half-open"
+            ],
+            "x-enum-varnames": [
+                "BreakerClosed",
+                "BreakerOpen",
+                "BreakerHalfOpen"
+            ]
+        },
+        "service.BreakerStatus": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "type": "integer"
+                },
+                "last_failure": {
+                    "type": "string"
+                },
+                "opened_at": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "retry_after": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/service.BreakerState"
+                }
+            }
+        },
+        "service.CacheStats": {
+            "type": "object",
+            "properties": {
+                "memory_hits": {
+                    "type": "integer"
+                },
+                "memory_size": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                },
+                "store_hits": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.DatasetStatus": {
+            "type": "object",
+            "properties": {
+                "loaded_at": {
+                    "type": "string"
+                },
+                "names": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.ProviderChainStatus": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "datasets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.DatasetStatus"
+                    }
+                },
+                "gender": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "nationality": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "type": "apiKey",
+            "name": "X-Admin-Token",
+            "in": "header"
         }
     }
 }`
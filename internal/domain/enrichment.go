@@ -0,0 +1,41 @@
+package domain
+
+// Fields of a person filled by enrichment.
+const (
+	FieldAge         = "age"
+	FieldGender      = "gender"
+	FieldNationality = "nationality"
+)
+
+var EnrichedFields = []string{FieldAge, FieldGender, FieldNationality}
+
+// AgePrediction is an age estimate and the number of samples it is based on.
+// Source names the provider that produced it.
+type AgePrediction struct {
+	Age    int
+	Count  int
+	Source string
+}
+
+// GenderPrediction is a gender estimate with its probability and the number
+// of samples it is based on.
+type GenderPrediction struct {
+	Gender      string
+	Probability float64
+	Count       int
+	Source      string
+}
+
+// NationalityPrediction is the most likely country of a name together with
+// every candidate country the provider returned.
+type NationalityPrediction struct {
+	CountryID   string
+	Probability float64
+	Candidates  []NationalityCandidate
+	Source      string
+}
+
+type NationalityCandidate struct {
+	CountryID   string
+	Probability float64
+}
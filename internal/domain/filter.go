@@ -1,12 +1,59 @@
 package domain
 
+import "time"
+
 type PersonFilter struct {
 	Name        *string
 	Surname     *string
+	Patronymic  *string
 	Gender      *string
 	Nationality *string
 	MinAge      *int
 	MaxAge      *int
-	Page        int
-	Size        int
+
+	MinAgeCount               *int
+	MinGenderProbability      *float64
+	MinGenderCount            *int
+	MinNationalityProbability *float64
+
+	CandidateCountry        *string
+	MinCandidateProbability *float64
+
+	EnrichmentStatus *string
+	// StaleSince matches persons not enriched since the given time.
+	StaleSince *time.Time
+	// MissingFields matches persons missing any of the named attributes.
+	MissingFields []string
+
+	WithNationalities bool
+	WithProvenance    bool
+
+	// IncludeDeleted also matches persons in the trash; OnlyDeleted matches
+	// nothing else.
+	IncludeDeleted bool
+	OnlyDeleted    bool
+
+	// AsOf matches persons as they were at the given time instead of now.
+	AsOf *time.Time
+
+	Sort []SortField
+
+	Page int
+	Size int
+}
+
+// SortField orders persons by Field, descending when Desc is set.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+const (
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+	SortDeletedAt = "deleted_at"
+)
+
+var SortableFields = []string{
+	FieldName, FieldSurname, FieldPatronymic, FieldAge, FieldGender, FieldNationality, SortCreatedAt, SortUpdatedAt, SortDeletedAt,
 }
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions recorded in the history of a person.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionEnrich  = "enrich"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionRevert  = "revert"
+)
+
+// Audit names who made a change and in which request.
+type Audit struct {
+	Actor     string
+	RequestID string
+}
+
+// PersonSnapshot holds the values of a person at one version.
+type PersonSnapshot struct {
+	Name                   string
+	Surname                string
+	Patronymic             string
+	Age                    int
+	AgeCount               int
+	AgeSource              string
+	Gender                 string
+	GenderProbability      float64
+	GenderCount            int
+	GenderSource           string
+	Nationality            string
+	NationalityProbability float64
+	NationalitySource      string
+	EnrichmentStatus       string
+	DeletedAt              *time.Time
+}
+
+// NewPersonSnapshot returns the current values of person.
+func NewPersonSnapshot(person *Person) *PersonSnapshot {
+	return &PersonSnapshot{
+		Name:                   person.Name,
+		Surname:                person.Surname,
+		Patronymic:             person.Patronymic,
+		Age:                    person.Age,
+		AgeCount:               person.AgeCount,
+		AgeSource:              person.AgeSource,
+		Gender:                 person.Gender,
+		GenderProbability:      person.GenderProbability,
+		GenderCount:            person.GenderCount,
+		GenderSource:           person.GenderSource,
+		Nationality:            person.Nationality,
+		NationalityProbability: person.NationalityProbability,
+		NationalitySource:      person.NationalitySource,
+		EnrichmentStatus:       person.EnrichmentStatus,
+		DeletedAt:              person.DeletedAt,
+	}
+}
+
+// PersonChange is one entry in the history of a person: the action that
+// moved it to Version, with its values before and after and the fields that
+// differ between them. Before is nil for a create.
+type PersonChange struct {
+	ID        int64
+	PersonID  uuid.UUID
+	Version   int
+	Action    string
+	Before    *PersonSnapshot
+	After     *PersonSnapshot
+	Diff      []FieldDiff
+	Actor     string
+	RequestID string
+	CreatedAt time.Time
+}
+
+// FieldDiff is a field whose value changed from Before to After.
+type FieldDiff struct {
+	Field  string
+	Before any
+	After  any
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+const (
+	OperationRunning   = "running"
+	OperationCompleted = "completed"
+)
+
+// EnrichmentJob is a queued request to enrich one person. Fields lists the
+// fields to enrich, all of them when empty, and Force includes locked ones.
+type EnrichmentJob struct {
+	ID          uuid.UUID
+	PersonID    uuid.UUID
+	CountryID   string
+	OperationID *uuid.UUID
+	Fields      []string
+	Force       bool
+	Status      string
+	Attempts    int
+	MaxAttempts int
+	LastError   string
+	RunAfter    time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// EnrichmentOperation tracks a bulk re-enrichment. Its progress is the
+// state of the jobs it queued.
+type EnrichmentOperation struct {
+	ID        uuid.UUID
+	Status    string
+	Total     int
+	Pending   int
+	Running   int
+	Done      int
+	Failed    int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -6,15 +6,44 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	EnrichmentPending   = "pending"
+	EnrichmentCompleted = "completed"
+	EnrichmentPartial   = "partial"
+	EnrichmentFailed    = "failed"
+)
+
+// Person is a registry entry. Names are stored as entered and, in the
+// *Latin fields, transliterated to the Latin script sent to the enrichment
+// APIs. EnrichmentErrors holds the reason each failed field was not enriched
+// and Provenance where each field value came from, both keyed by field.
+// Version counts the writes to the person and guards against lost updates.
+// DeletedAt is set while the person is in the trash.
 type Person struct {
-	ID          uuid.UUID
-	Name        string
-	Surname     string
-	Age        	int
-	Gender      string
-	Nationality string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	DeletedAt   time.Time
+	ID                     uuid.UUID
+	Name                   string
+	Surname                string
+	Patronymic             string
+	NameLatin              string
+	SurnameLatin           string
+	PatronymicLatin        string
+	Age                    int
+	AgeCount               int
+	AgeSource              string
+	Gender                 string
+	GenderProbability      float64
+	GenderCount            int
+	GenderSource           string
+	Nationality            string
+	NationalityProbability float64
+	NationalitySource      string
+	Nationalities          []NationalityCandidate
+	EnrichmentStatus       string
+	EnrichmentErrors       map[string]string
+	EnrichedAt             *time.Time
+	Provenance             map[string]FieldProvenance
+	Version                int
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+	DeletedAt              *time.Time
 }
-
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+const (
+	FieldName       = "name"
+	FieldSurname    = "surname"
+	FieldPatronymic = "patronymic"
+)
+
+// Sources of a field value other than the enrichment providers, whose names
+// are used as is.
+const (
+	SourceManual = "manual"
+	SourceImport = "import"
+)
+
+// ActorSystem is the actor of changes made by the service itself.
+const ActorSystem = "system"
+
+// FieldProvenance records where the current value of a person field came
+// from. A locked field is skipped by enrichment unless it is forced.
+type FieldProvenance struct {
+	Source    string
+	Actor     string
+	UpdatedAt time.Time
+	Locked    bool
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type EnrichmentCacheRepository struct {
+	db *pgxpool.Pool
+}
+
+var (
+	ErrCacheEntryNotFound = errors.New("cache entry not found")
+)
+
+func NewEnrichmentCacheRepository(db *pgxpool.Pool) *EnrichmentCacheRepository {
+	return &EnrichmentCacheRepository{db: db}
+}
+
+func (r *EnrichmentCacheRepository) GetEntry(ctx context.Context, name, attribute, countryID string) ([]byte, time.Time, error) {
+	var (
+		value     []byte
+		expiresAt time.Time
+	)
+
+	query := `
+			SELECT
+				value,
+				expires_at
+			FROM name_enrichment_cache
+			WHERE name = $1 AND attribute = $2 AND country_id = $3 AND expires_at > NOW()
+			`
+	err := r.db.QueryRow(ctx, query, name, attribute, countryID).Scan(&value, &expiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, time.Time{}, ErrCacheEntryNotFound
+		}
+		return nil, time.Time{}, fmt.Errorf("failed to get cache entry: %w", err)
+	}
+	return value, expiresAt, nil
+}
+
+func (r *EnrichmentCacheRepository) SaveEntry(ctx context.Context, name, attribute, countryID string, value []byte, expiresAt time.Time) error {
+	query := `
+		INSERT INTO name_enrichment_cache (
+			name,
+			attribute,
+			country_id,
+			value,
+			expires_at,
+			created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, NOW()
+		)
+		ON CONFLICT (name, attribute, country_id) DO UPDATE
+		SET value = EXCLUDED.value,
+			expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at`
+
+	if _, err := r.db.Exec(ctx, query, name, attribute, countryID, value, expiresAt); err != nil {
+		return fmt.Errorf("failed to save cache entry: %w", err)
+	}
+	return nil
+}
+
+func (r *EnrichmentCacheRepository) DeleteByName(ctx context.Context, name string) (int64, error) {
+	query := `DELETE FROM name_enrichment_cache WHERE name = $1`
+
+	tag, err := r.db.Exec(ctx, query, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete cache entries by name: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *EnrichmentCacheRepository) DeleteAll(ctx context.Context) (int64, error) {
+	query := `DELETE FROM name_enrichment_cache`
+
+	tag, err := r.db.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete cache entries: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
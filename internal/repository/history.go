@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"Effective/internal/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrVersionNotFound = errors.New("person version not found")
+
+// History returns the changes of a person, newest first.
+func (r *PersonRepository) History(ctx context.Context, id uuid.UUID) ([]domain.PersonChange, error) {
+	query := `
+			SELECT id, person_id, version, action, snapshot_before, snapshot_after, actor, request_id, created_at
+			FROM person_history
+			WHERE person_id = $1
+			ORDER BY version DESC, id DESC
+			`
+	rows, err := r.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get person history: %w", err)
+	}
+	defer rows.Close()
+
+	changes := make([]domain.PersonChange, 0)
+	for rows.Next() {
+		var change domain.PersonChange
+		if err := rows.Scan(
+			&change.ID,
+			&change.PersonID,
+			&change.Version,
+			&change.Action,
+			&change.Before,
+			&change.After,
+			&change.Actor,
+			&change.RequestID,
+			&change.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan person change: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// GetSnapshot returns the values a person had at version. It fails with
+// ErrVersionNotFound when no change moved the person to version.
+func (r *PersonRepository) GetSnapshot(ctx context.Context, id uuid.UUID, version int) (*domain.PersonSnapshot, error) {
+	var snapshot domain.PersonSnapshot
+
+	query := `
+			SELECT snapshot_after
+			FROM person_history
+			WHERE person_id = $1 AND version = $2
+			ORDER BY id DESC
+			LIMIT 1
+			`
+	if err := r.db.QueryRow(ctx, query, id, version).Scan(&snapshot); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, fmt.Errorf("failed to get person snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// GetNationalities returns the nationality candidates a person had at
+// version, as they stood when the change to version was made.
+func (r *PersonRepository) GetNationalities(ctx context.Context, id uuid.UUID, version int) ([]domain.NationalityCandidate, error) {
+	query := `
+			SELECT c.country_id, c.probability
+			FROM person_nationality_candidate_versions c
+			JOIN (
+				SELECT created_at
+				FROM person_history
+				WHERE person_id = $1 AND version = $2
+				ORDER BY id DESC
+				LIMIT 1
+			) h ON c.valid_from <= h.created_at AND (c.valid_to IS NULL OR c.valid_to > h.created_at)
+			WHERE c.person_id = $1
+			ORDER BY c.probability DESC
+			`
+	rows, err := r.db.Query(ctx, query, id, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nationality candidates: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := make([]domain.NationalityCandidate, 0)
+	for rows.Next() {
+		var candidate domain.NationalityCandidate
+		if err := rows.Scan(&candidate.CountryID, &candidate.Probability); err != nil {
+			return nil, fmt.Errorf("failed to scan nationality candidate: %w", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, rows.Err()
+}
+
+// lockPerson reads a person for update inside tx, from the trash when
+// deleted is set. It fails with ErrUserNotFound when there is no such person.
+func lockPerson(ctx context.Context, tx pgx.Tx, id uuid.UUID, deleted bool) (*domain.Person, error) {
+	var person domain.Person
+
+	query := `
+			SELECT ` + personColumns + `
+			FROM persons
+			WHERE id = $1 AND (deleted_at IS NOT NULL) = $2
+			FOR UPDATE
+			`
+	if err := scanPerson(tx.QueryRow(ctx, query, id, deleted), &person); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to lock person: %w", err)
+	}
+	return &person, nil
+}
+
+// recordChange appends the change that moved a person to version to its
+// history. before is nil for a create.
+func recordChange(ctx context.Context, tx pgx.Tx, personID uuid.UUID, version int, action string, before, after *domain.Person, audit domain.Audit) error {
+	var snapshotBefore []byte
+	if before != nil {
+		b, err := json.Marshal(domain.NewPersonSnapshot(before))
+		if err != nil {
+			return fmt.Errorf("failed to encode person snapshot: %w", err)
+		}
+		snapshotBefore = b
+	}
+	snapshotAfter, err := json.Marshal(domain.NewPersonSnapshot(after))
+	if err != nil {
+		return fmt.Errorf("failed to encode person snapshot: %w", err)
+	}
+
+	query := `
+		INSERT INTO person_history (
+			person_id,
+			version,
+			action,
+			snapshot_before,
+			snapshot_after,
+			actor,
+			request_id
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		)`
+
+	if _, err := tx.Exec(ctx, query, personID, version, action, snapshotBefore, snapshotAfter, audit.Actor, audit.RequestID); err != nil {
+		return fmt.Errorf("failed to record person change: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"Effective/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrOperationNotFound = errors.New("enrichment operation not found")
+)
+
+type EnrichmentJobRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEnrichmentJobRepository(db *pgxpool.Pool) *EnrichmentJobRepository {
+	return &EnrichmentJobRepository{db: db}
+}
+
+func (r *EnrichmentJobRepository) EnqueueJob(ctx context.Context, job *domain.EnrichmentJob) (uuid.UUID, error) {
+	return insertEnrichmentJob(ctx, r.db, job)
+}
+
+// ClaimJobs marks up to limit due jobs as running and returns them. Jobs are
+// locked with FOR UPDATE SKIP LOCKED so concurrent workers never claim the
+// same job; a running job whose lease expired is claimed again.
+func (r *EnrichmentJobRepository) ClaimJobs(ctx context.Context, limit int, lease time.Duration) ([]domain.EnrichmentJob, error) {
+	query := `
+		UPDATE enrichment_jobs
+		SET status = 'running',
+			attempts = attempts + 1,
+			locked_at = NOW(),
+			updated_at = NOW()
+		WHERE id IN (
+			SELECT id
+			FROM enrichment_jobs
+			WHERE (status = 'pending' AND run_after <= NOW())
+				OR (status = 'running' AND locked_at < NOW() - make_interval(secs => $2))
+			ORDER BY run_after
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, person_id, country_id, operation_id, fields, force, status, attempts, max_attempts, last_error, run_after, created_at, updated_at`
+
+	rows, err := r.db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim enrichment jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]domain.EnrichmentJob, 0, limit)
+	for rows.Next() {
+		var job domain.EnrichmentJob
+		if err := rows.Scan(
+			&job.ID,
+			&job.PersonID,
+			&job.CountryID,
+			&job.OperationID,
+			&job.Fields,
+			&job.Force,
+			&job.Status,
+			&job.Attempts,
+			&job.MaxAttempts,
+			&job.LastError,
+			&job.RunAfter,
+			&job.CreatedAt,
+			&job.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan enrichment job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func (r *EnrichmentJobRepository) CompleteJob(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE enrichment_jobs SET status = 'done', locked_at = NULL, updated_at = NOW() WHERE id = $1`
+
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to complete enrichment job: %w", err)
+	}
+	return nil
+}
+
+// FailJob records reason and schedules the job again at retryAt. A job that
+// has used all its attempts is failed for good, and so is its person's
+// enrichment if it is still pending.
+func (r *EnrichmentJobRepository) FailJob(ctx context.Context, job *domain.EnrichmentJob, reason string, retryAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	status := domain.JobPending
+	if job.Attempts >= job.MaxAttempts {
+		status = domain.JobFailed
+	}
+
+	query := `
+		UPDATE enrichment_jobs
+		SET status = $1,
+			last_error = $2,
+			run_after = $3,
+			locked_at = NULL,
+			updated_at = NOW()
+		WHERE id = $4`
+	if _, err := tx.Exec(ctx, query, status, reason, retryAt, job.ID); err != nil {
+		return fmt.Errorf("failed to fail enrichment job: %w", err)
+	}
+
+	if status == domain.JobFailed {
+		if err := failPersonEnrichment(ctx, tx, job.PersonID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit enrichment job: %w", err)
+	}
+	return nil
+}
+
+// failPersonEnrichment marks the enrichment of a person that is still
+// pending as failed and records the change in its history.
+func failPersonEnrichment(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	before, err := lockPerson(ctx, tx, id, false)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if before.EnrichmentStatus != domain.EnrichmentPending {
+		return nil
+	}
+
+	after := *before
+	after.EnrichmentStatus = domain.EnrichmentFailed
+	query := `UPDATE persons
+				SET enrichment_status = $1, version = version + 1, updated_at = NOW()
+				WHERE id = $2
+				RETURNING version, updated_at`
+	if err := tx.QueryRow(ctx, query, after.EnrichmentStatus, id).Scan(&after.Version, &after.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to mark person enrichment failed: %w", err)
+	}
+
+	return recordChange(ctx, tx, id, after.Version, domain.ActionEnrich, before, &after, domain.Audit{Actor: domain.ActorSystem})
+}
+
+// CreateOperation queues a re-enrichment job for every person matching
+// filter and returns the operation that tracks them. Persons in the trash are
+// never queued, even when filter includes them.
+func (r *EnrichmentJobRepository) CreateOperation(ctx context.Context, filter *domain.PersonFilter, maxAttempts int, force bool) (*domain.EnrichmentOperation, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	operation := &domain.EnrichmentOperation{Status: domain.OperationRunning}
+	query := `INSERT INTO enrichment_operations DEFAULT VALUES RETURNING id, created_at`
+	if err := tx.QueryRow(ctx, query).Scan(&operation.ID, &operation.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to create enrichment operation: %w", err)
+	}
+
+	persons := filterPersons(sq.Select("id").
+		Column("?::integer", maxAttempts).
+		Column("?::uuid", operation.ID).
+		Column("?::boolean", force).
+		From("persons"), filter).
+		Where(sq.Eq{"deleted_at": nil})
+	insert, values, err := sq.Insert("enrichment_jobs").
+		Columns("person_id", "max_attempts", "operation_id", "force").
+		Select(persons).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, insert, values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enqueue enrichment jobs: %w", err)
+	}
+	operation.Total = int(tag.RowsAffected())
+	operation.Pending = operation.Total
+	operation.UpdatedAt = operation.CreatedAt
+	if operation.Total == 0 {
+		operation.Status = domain.OperationCompleted
+	}
+
+	query = `UPDATE enrichment_operations SET total = $1 WHERE id = $2`
+	if _, err := tx.Exec(ctx, query, operation.Total, operation.ID); err != nil {
+		return nil, fmt.Errorf("failed to update enrichment operation: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit enrichment operation: %w", err)
+	}
+	return operation, nil
+}
+
+// GetOperation returns the operation with its progress counted from the
+// state of its jobs.
+func (r *EnrichmentJobRepository) GetOperation(ctx context.Context, id uuid.UUID) (*domain.EnrichmentOperation, error) {
+	operation := &domain.EnrichmentOperation{}
+
+	query := `
+		SELECT
+			o.id,
+			o.total,
+			COUNT(*) FILTER (WHERE j.status = 'pending'),
+			COUNT(*) FILTER (WHERE j.status = 'running'),
+			COUNT(*) FILTER (WHERE j.status = 'done'),
+			COUNT(*) FILTER (WHERE j.status = 'failed'),
+			o.created_at,
+			COALESCE(MAX(j.updated_at), o.created_at)
+		FROM enrichment_operations o
+		LEFT JOIN enrichment_jobs j ON j.operation_id = o.id
+		WHERE o.id = $1
+		GROUP BY o.id`
+
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&operation.ID,
+		&operation.Total,
+		&operation.Pending,
+		&operation.Running,
+		&operation.Done,
+		&operation.Failed,
+		&operation.CreatedAt,
+		&operation.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOperationNotFound
+		}
+		return nil, fmt.Errorf("failed to get enrichment operation: %w", err)
+	}
+
+	operation.Status = domain.OperationCompleted
+	if operation.Pending+operation.Running > 0 {
+		operation.Status = domain.OperationRunning
+	}
+	return operation, nil
+}
+
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func insertEnrichmentJob(ctx context.Context, db rowQuerier, job *domain.EnrichmentJob) (uuid.UUID, error) {
+	var id uuid.UUID
+
+	fields := job.Fields
+	if fields == nil {
+		fields = []string{}
+	}
+
+	query := `
+		INSERT INTO enrichment_jobs (
+			person_id,
+			country_id,
+			fields,
+			force,
+			max_attempts
+		) VALUES (
+			$1, $2, $3, $4, $5
+		)
+		RETURNING id`
+
+	if err := db.QueryRow(ctx, query, job.PersonID, job.CountryID, fields, job.Force, job.MaxAttempts).Scan(&id); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to enqueue enrichment job: %w", err)
+	}
+	return id, nil
+}
@@ -6,6 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
@@ -18,159 +21,372 @@ type PersonRepository struct {
 }
 
 var (
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrVersionConflict = errors.New("person version conflict")
 )
 
 func NewPersonRepository(db *pgxpool.Pool) *PersonRepository {
 	return &PersonRepository{db: db}
 }
 
-func (r *PersonRepository) SavePerson(ctx context.Context, person *domain.Person) (uuid.UUID, error) {
-	var id uuid.UUID
+func (r *PersonRepository) SavePerson(ctx context.Context, person *domain.Person, audit domain.Audit) (uuid.UUID, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-	query := `
-		INSERT INTO persons (
-			name,
-			surname,
-			age,
-			gender,
-			nationality,
-			created_at,
-			updated_at
-		) VALUES (
-			$1, $2, $3, $4, $5, NOW(), NOW()
-		)
-		RETURNING id`
+	id, err := insertPerson(ctx, tx, person, audit)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to commit person: %w", err)
+	}
+
+	return id, nil
+}
+
+// SavePersonWithJob stores a person together with the job that will enrich
+// it.
+func (r *PersonRepository) SavePersonWithJob(ctx context.Context, person *domain.Person, job *domain.EnrichmentJob, audit domain.Audit) (uuid.UUID, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	id, err := insertPerson(ctx, tx, person, audit)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	job.PersonID = id
+	if _, err := insertEnrichmentJob(ctx, tx, job); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to commit person: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateEnrichment stores the enriched fields, nationality candidates and
+// enrichment status of person, leaving name and surname untouched. The
+// person must still be at person.Version, which then moves to the new
+// version; otherwise it fails with ErrVersionConflict. The change is
+// recorded in the person's history.
+func (r *PersonRepository) UpdateEnrichment(ctx context.Context, person *domain.Person, audit domain.Audit) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-	err := r.db.QueryRow(
+	before, err := lockPerson(ctx, tx, person.ID, false)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE persons
+				SET
+					age = $1,
+					age_count = $2,
+					age_source = $3,
+					gender = $4,
+					gender_probability = $5,
+					gender_count = $6,
+					gender_source = $7,
+					nationality = $8,
+					nationality_probability = $9,
+					nationality_source = $10,
+					enrichment_status = $11,
+					enrichment_errors = $12,
+					enriched_at = $13,
+					version = version + 1,
+					updated_at = NOW()
+				WHERE id = $14 AND version = $15 AND deleted_at IS NULL
+				RETURNING version, updated_at`
+
+	err = tx.QueryRow(
 		ctx,
 		query,
-		person.Name,
-		person.Surname,
 		person.Age,
+		person.AgeCount,
+		person.AgeSource,
 		person.Gender,
+		person.GenderProbability,
+		person.GenderCount,
+		person.GenderSource,
 		person.Nationality,
-	).Scan(&id)
-
+		person.NationalityProbability,
+		person.NationalitySource,
+		person.EnrichmentStatus,
+		enrichmentErrors(person),
+		person.EnrichedAt,
+		person.ID,
+		person.Version,
+	).Scan(&person.Version, &person.UpdatedAt)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to create user: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return versionConflict(ctx, tx, person.ID)
+		}
+		return fmt.Errorf("failed to update person enrichment: %w", err)
 	}
 
-	return id, nil
+	if err := recordChange(ctx, tx, person.ID, person.Version, domain.ActionEnrich, before, person, audit); err != nil {
+		return err
+	}
+
+	if err := saveNationalityCandidates(ctx, tx, person.ID, person.Nationalities); err != nil {
+		return err
+	}
+
+	if err := saveProvenance(ctx, tx, person.ID, person.Provenance); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit person enrichment: %w", err)
+	}
+	return nil
 }
 
+// GetByID returns a person that is not in the trash.
 func (r *PersonRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Person, error) {
+	return r.FindByID(ctx, id, false)
+}
+
+// FindByID returns a person, also from the trash when includeDeleted is set.
+func (r *PersonRepository) FindByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*domain.Person, error) {
 	person := domain.Person{}
 
 	query := `
-			SELECT 
-				id,
-				name,
-				surname,
-				gender,
-				nationality,
-				created_at,
-				updated_at
+			SELECT ` + personColumns + `
 			FROM persons
-			WHERE id=$1
+			WHERE id=$1 AND ($2 OR deleted_at IS NULL)
 			`
-	err := r.db.QueryRow(
+	err := scanPerson(r.db.QueryRow(
 		ctx,
 		query,
 		id,
-	).Scan(&person.ID,
-		&person.Name,
-		&person.Surname,
-		&person.Gender,
-		&person.Nationality,
-		&person.CreatedAt,
-		&person.UpdatedAt,
-	)
+		includeDeleted,
+	), &person)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get person by id: %w", err)
 	}
-	return &person, nil
+
+	persons := []domain.Person{person}
+	if err := r.loadNationalities(ctx, persons, nil); err != nil {
+		return nil, err
+	}
+	if err := r.loadProvenance(ctx, persons); err != nil {
+		return nil, err
+	}
+	return &persons[0], nil
 }
 
-func (r *PersonRepository) DeleteByID(ctx context.Context, id uuid.UUID) (bool, error) {
-	query := `UPDATE persons SET deleted_at = NOW() WHERE id = $1`
+// FindAsOf returns a person as it was at asOf, also from the trash when
+// includeDeleted is set. Field provenance is not versioned and is left out.
+func (r *PersonRepository) FindAsOf(ctx context.Context, id uuid.UUID, asOf time.Time, includeDeleted bool) (*domain.Person, error) {
+	person := domain.Person{}
 
-	_, err := r.db.Exec(ctx, query, id)
+	query := `
+			SELECT ` + personColumns + `
+			FROM person_versions
+			WHERE id=$1 AND ($2 OR deleted_at IS NULL)
+				AND valid_from <= $3 AND (valid_to IS NULL OR valid_to > $3)
+			`
+	err := scanPerson(r.db.QueryRow(
+		ctx,
+		query,
+		id,
+		includeDeleted,
+		asOf,
+	), &person)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return false, ErrUserNotFound
+			return nil, ErrUserNotFound
 		}
+		return nil, fmt.Errorf("failed to get person as of %s: %w", asOf.Format(time.RFC3339), err)
+	}
+
+	persons := []domain.Person{person}
+	if err := r.loadNationalities(ctx, persons, &asOf); err != nil {
+		return nil, err
+	}
+	return &persons[0], nil
+}
+
+// DeleteByID soft-deletes a person. With a version the person must still be
+// at it, or ErrVersionConflict is returned. It returns false for an unknown
+// or already deleted person.
+func (r *PersonRepository) DeleteByID(ctx context.Context, id uuid.UUID, version *int, audit domain.Audit) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := lockPerson(ctx, tx, id, false)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if version != nil && *version != before.Version {
+		return false, ErrVersionConflict
+	}
+
+	after := *before
+	query := `UPDATE persons
+				SET deleted_at = NOW(), version = version + 1, updated_at = NOW()
+				WHERE id = $1
+				RETURNING version, deleted_at, updated_at`
+	if err := tx.QueryRow(ctx, query, id).Scan(&after.Version, &after.DeletedAt, &after.UpdatedAt); err != nil {
 		return false, fmt.Errorf("failed to delete person by id: %w", err)
 	}
+
+	if err := recordChange(ctx, tx, id, after.Version, domain.ActionDelete, before, &after, audit); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit person deletion: %w", err)
+	}
 	return true, nil
 }
 
-func (r *PersonRepository) UpdatePerson(ctx context.Context, person *domain.Person) error {
-	query := `UPDATE persons 
-				SET 
-					name = $1,
-					surname = $2,
-					age = $3,
-					gender = $4,
-					nationality = $5,
-					updated_at = NOW()
-				WHERE id = $6
-				RETURNING id, name, surname, age, gender, nationality, updated_at`
+// RestoreByID takes a person out of the trash. It fails with ErrUserNotFound
+// when the person is not in the trash.
+func (r *PersonRepository) RestoreByID(ctx context.Context, id uuid.UUID, audit domain.Audit) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-	_, err := r.db.Exec(
-		ctx,
-		query,
-		person.Name,
-		person.Surname,
-		person.Age,
-		person.Gender,
-		person.Nationality,
-		person.ID,
-	)
+	before, err := lockPerson(ctx, tx, id, true)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return ErrUserNotFound
-		}
-		return fmt.Errorf("failed to update person: %w", err)
+		return err
+	}
+
+	after := *before
+	after.DeletedAt = nil
+	query := `UPDATE persons
+				SET deleted_at = NULL, version = version + 1, updated_at = NOW()
+				WHERE id = $1
+				RETURNING version, updated_at`
+	if err := tx.QueryRow(ctx, query, id).Scan(&after.Version, &after.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to restore person: %w", err)
+	}
+
+	if err := recordChange(ctx, tx, id, after.Version, domain.ActionRestore, before, &after, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit person restore: %w", err)
 	}
 	return nil
 }
 
-func (r *PersonRepository) GetPersonFilter(ctx context.Context, person *domain.PersonFilter) (*[]domain.Person, error) {
-	query := sq.Select("id,name,surname,age,gender,nationality, created_at, updated_at").From("persons").PlaceholderFormat(sq.Dollar)
+// PurgeDeleted hard-deletes the persons moved to the trash before before and
+// returns how many were removed. Their candidates, provenance and jobs go
+// with them.
+func (r *PersonRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM persons WHERE deleted_at IS NOT NULL AND deleted_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted persons: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
 
-	if person.Name != nil {
-		query = query.Where(sq.Eq{"name": *person.Name})
+// UpdatePerson saves person if it is still at person.Version, which then
+// moves to the new version. It fails with ErrVersionConflict when the person
+// changed since it was read. The change is recorded in the person's history
+// under action. When the nationality or its source changes, the nationality
+// candidates are replaced by person.Nationalities.
+func (r *PersonRepository) UpdatePerson(ctx context.Context, person *domain.Person, action string, audit domain.Audit) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	if person.Surname != nil {
-		query = query.Where(sq.Eq{"surname": *person.Surname})
+	before, err := lockPerson(ctx, tx, person.ID, false)
+	if err != nil {
+		return err
 	}
 
-	if person.MinAge != nil && person.MaxAge != nil {
-		query = query.Where(sq.And{
-			sq.GtOrEq{"age": *person.MinAge},
-			sq.LtOrEq{"age": *person.MaxAge},
-		})
-	} else if person.MinAge != nil {
-		query = query.Where(sq.GtOrEq{"age": *person.MinAge})
-	} else if person.MaxAge != nil {
-		query = query.Where(sq.LtOrEq{"age": *person.MaxAge})
+	query, args, err := sq.Update("persons").
+		Set("name", person.Name).
+		Set("surname", person.Surname).
+		Set("patronymic", person.Patronymic).
+		Set("name_latin", person.NameLatin).
+		Set("surname_latin", person.SurnameLatin).
+		Set("patronymic_latin", person.PatronymicLatin).
+		Set("age", person.Age).
+		Set("age_count", person.AgeCount).
+		Set("age_source", person.AgeSource).
+		Set("gender", person.Gender).
+		Set("gender_probability", person.GenderProbability).
+		Set("gender_count", person.GenderCount).
+		Set("gender_source", person.GenderSource).
+		Set("nationality", person.Nationality).
+		Set("nationality_probability", person.NationalityProbability).
+		Set("nationality_source", person.NationalitySource).
+		Set("version", sq.Expr("version + 1")).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": person.ID}).
+		Where(sq.Eq{"version": person.Version}).
+		Where(sq.Eq{"deleted_at": nil}).
+		Suffix("RETURNING version, updated_at").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
 	}
 
-	if person.MaxAge != nil {
-		query = query.Where(sq.LtOrEq{"age": *person.MaxAge})
+	err = tx.QueryRow(ctx, query, args...).Scan(&person.Version, &person.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return versionConflict(ctx, tx, person.ID)
+		}
+		return fmt.Errorf("failed to update person: %w", err)
 	}
-	if person.Gender != nil {
-		query = query.Where(sq.Eq{"gender": *person.Gender})
+
+	if err := recordChange(ctx, tx, person.ID, person.Version, action, before, person, audit); err != nil {
+		return err
 	}
-	if person.Nationality != nil {
-		query = query.Where(sq.Eq{"nationality": *person.Nationality})
+
+	if person.Nationality != before.Nationality || person.NationalitySource != before.NationalitySource {
+		if err := saveNationalityCandidates(ctx, tx, person.ID, person.Nationalities); err != nil {
+			return err
+		}
 	}
 
+	if err := saveProvenance(ctx, tx, person.ID, person.Provenance); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit person: %w", err)
+	}
+	return nil
+}
+
+func (r *PersonRepository) GetPersonFilter(ctx context.Context, person *domain.PersonFilter) (*[]domain.Person, error) {
+	query := filterPersons(sq.Select(personColumns).From(personsTable(person)).PlaceholderFormat(sq.Dollar), person)
+	query = sortPersons(query, person.Sort)
+
 	if person.Page <= 0 {
 		person.Page = 1
 	}
@@ -200,16 +416,7 @@ func (r *PersonRepository) GetPersonFilter(ctx context.Context, person *domain.P
 
 	for rows.Next() {
 		var pers domain.Person
-		if err := rows.Scan(
-			&pers.ID,
-			&pers.Name,
-			&pers.Surname,
-			&pers.Age,
-			&pers.Gender,
-			&pers.Nationality,
-			&pers.CreatedAt,
-			&pers.UpdatedAt,
-		); err != nil {
+		if err := scanPerson(rows, &pers); err != nil {
 			return nil, fmt.Errorf("failed to scan person: %w", err)
 		}
 		filterPerson = append(filterPerson, pers)
@@ -218,5 +425,453 @@ func (r *PersonRepository) GetPersonFilter(ctx context.Context, person *domain.P
 	if len(filterPerson) == 0 {
 		return nil, fmt.Errorf("no person found with the given filter")
 	}
+
+	if person.WithNationalities {
+		if err := r.loadNationalities(ctx, filterPerson, person.AsOf); err != nil {
+			return nil, err
+		}
+	}
+	if person.WithProvenance {
+		if err := r.loadProvenance(ctx, filterPerson); err != nil {
+			return nil, err
+		}
+	}
 	return &filterPerson, nil
 }
+
+// ExportPersons calls fn for every person matching filter, ignoring
+// pagination, while streaming the rows.
+func (r *PersonRepository) ExportPersons(ctx context.Context, filter *domain.PersonFilter, fn func(*domain.Person) error) error {
+	query := filterPersons(sq.Select(personColumns).From(personsTable(filter)).PlaceholderFormat(sq.Dollar), filter)
+	query = sortPersons(query, filter.Sort)
+
+	q, values, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, q, values...)
+	if err != nil {
+		return fmt.Errorf("failed to export persons: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var person domain.Person
+		if err := scanPerson(rows, &person); err != nil {
+			return fmt.Errorf("failed to scan person: %w", err)
+		}
+		if err := fn(&person); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// loadNationalities fills in the nationality candidates of persons, as they
+// were at asOf when it is set.
+func (r *PersonRepository) loadNationalities(ctx context.Context, persons []domain.Person, asOf *time.Time) error {
+	ids := make([]uuid.UUID, 0, len(persons))
+	index := make(map[uuid.UUID]int, len(persons))
+	for i := range persons {
+		ids = append(ids, persons[i].ID)
+		index[persons[i].ID] = i
+	}
+
+	query := `
+			SELECT person_id, country_id, probability
+			FROM person_nationality_candidates
+			WHERE person_id = ANY($1)
+			ORDER BY person_id, probability DESC
+			`
+	args := []any{ids}
+	if asOf != nil {
+		query = `
+			SELECT person_id, country_id, probability
+			FROM person_nationality_candidate_versions
+			WHERE person_id = ANY($1) AND valid_from <= $2 AND (valid_to IS NULL OR valid_to > $2)
+			ORDER BY person_id, probability DESC
+			`
+		args = append(args, *asOf)
+	}
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to get nationality candidates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			personID  uuid.UUID
+			candidate domain.NationalityCandidate
+		)
+		if err := rows.Scan(&personID, &candidate.CountryID, &candidate.Probability); err != nil {
+			return fmt.Errorf("failed to scan nationality candidate: %w", err)
+		}
+		i := index[personID]
+		persons[i].Nationalities = append(persons[i].Nationalities, candidate)
+	}
+	return rows.Err()
+}
+
+// versionConflict tells why a versioned write of the person id matched no
+// row: ErrUserNotFound when it is gone, ErrVersionConflict when its version
+// moved on.
+func versionConflict(ctx context.Context, db rowQuerier, id uuid.UUID) error {
+	var version int
+	err := db.QueryRow(ctx, `SELECT version FROM persons WHERE id = $1 AND deleted_at IS NULL`, id).Scan(&version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to check person version: %w", err)
+	}
+	return ErrVersionConflict
+}
+
+func insertPerson(ctx context.Context, tx pgx.Tx, person *domain.Person, audit domain.Audit) (uuid.UUID, error) {
+	var id uuid.UUID
+
+	query := `
+		INSERT INTO persons (
+			name,
+			surname,
+			age,
+			age_count,
+			age_source,
+			gender,
+			gender_probability,
+			gender_count,
+			gender_source,
+			nationality,
+			nationality_probability,
+			nationality_source,
+			enrichment_status,
+			enrichment_errors,
+			enriched_at,
+			patronymic,
+			name_latin,
+			surname_latin,
+			patronymic_latin,
+			created_at,
+			updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, NOW(), NOW()
+		)
+		RETURNING id, version`
+
+	err := tx.QueryRow(
+		ctx,
+		query,
+		person.Name,
+		person.Surname,
+		person.Age,
+		person.AgeCount,
+		person.AgeSource,
+		person.Gender,
+		person.GenderProbability,
+		person.GenderCount,
+		person.GenderSource,
+		person.Nationality,
+		person.NationalityProbability,
+		person.NationalitySource,
+		person.EnrichmentStatus,
+		enrichmentErrors(person),
+		person.EnrichedAt,
+		person.Patronymic,
+		person.NameLatin,
+		person.SurnameLatin,
+		person.PatronymicLatin,
+	).Scan(&id, &person.Version)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if err := saveNationalityCandidates(ctx, tx, id, person.Nationalities); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := saveProvenance(ctx, tx, id, person.Provenance); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := recordChange(ctx, tx, id, person.Version, domain.ActionCreate, nil, person, audit); err != nil {
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
+func (r *PersonRepository) loadProvenance(ctx context.Context, persons []domain.Person) error {
+	ids := make([]uuid.UUID, 0, len(persons))
+	index := make(map[uuid.UUID]int, len(persons))
+	for i := range persons {
+		ids = append(ids, persons[i].ID)
+		index[persons[i].ID] = i
+	}
+
+	query := `
+			SELECT person_id, field, source, actor, locked, updated_at
+			FROM person_field_provenance
+			WHERE person_id = ANY($1)
+			`
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get field provenance: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			personID   uuid.UUID
+			field      string
+			provenance domain.FieldProvenance
+		)
+		if err := rows.Scan(&personID, &field, &provenance.Source, &provenance.Actor, &provenance.Locked, &provenance.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to scan field provenance: %w", err)
+		}
+		i := index[personID]
+		if persons[i].Provenance == nil {
+			persons[i].Provenance = make(map[string]domain.FieldProvenance)
+		}
+		persons[i].Provenance[field] = provenance
+	}
+	return rows.Err()
+}
+
+// saveProvenance upserts the provenance of every field in provenance.
+func saveProvenance(ctx context.Context, tx pgx.Tx, personID uuid.UUID, provenance map[string]domain.FieldProvenance) error {
+	query := `
+		INSERT INTO person_field_provenance (
+			person_id,
+			field,
+			source,
+			actor,
+			locked,
+			updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+		ON CONFLICT (person_id, field) DO UPDATE SET
+			source = EXCLUDED.source,
+			actor = EXCLUDED.actor,
+			locked = EXCLUDED.locked,
+			updated_at = EXCLUDED.updated_at`
+
+	batch := &pgx.Batch{}
+	for field, p := range provenance {
+		batch.Queue(query, personID, field, p.Source, p.Actor, p.Locked, p.UpdatedAt)
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to save field provenance: %w", err)
+	}
+	return nil
+}
+
+// enrichmentErrors returns the per-field errors of person for the NOT NULL
+// enrichment_errors column.
+func enrichmentErrors(person *domain.Person) map[string]string {
+	if person.EnrichmentErrors == nil {
+		return map[string]string{}
+	}
+	return person.EnrichmentErrors
+}
+
+func saveNationalityCandidates(ctx context.Context, tx pgx.Tx, personID uuid.UUID, candidates []domain.NationalityCandidate) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM person_nationality_candidates WHERE person_id = $1`, personID); err != nil {
+		return fmt.Errorf("failed to clear nationality candidates: %w", err)
+	}
+
+	query := `
+		INSERT INTO person_nationality_candidates (
+			person_id,
+			country_id,
+			probability
+		) VALUES (
+			$1, $2, $3
+		)
+		ON CONFLICT (person_id, country_id) DO UPDATE SET probability = EXCLUDED.probability`
+
+	batch := &pgx.Batch{}
+	for _, candidate := range candidates {
+		batch.Queue(query, personID, strings.ToUpper(candidate.CountryID), candidate.Probability)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to save nationality candidates: %w", err)
+	}
+	return nil
+}
+
+const personColumns = `id, name, surname, patronymic, name_latin, surname_latin, patronymic_latin,
+	age, age_count, age_source, gender, gender_probability, gender_count, gender_source, nationality, nationality_probability, nationality_source,
+	enrichment_status, enrichment_errors, enriched_at, version, created_at, updated_at, deleted_at`
+
+func scanPerson(row pgx.Row, person *domain.Person) error {
+	return row.Scan(
+		&person.ID,
+		&person.Name,
+		&person.Surname,
+		&person.Patronymic,
+		&person.NameLatin,
+		&person.SurnameLatin,
+		&person.PatronymicLatin,
+		&person.Age,
+		&person.AgeCount,
+		&person.AgeSource,
+		&person.Gender,
+		&person.GenderProbability,
+		&person.GenderCount,
+		&person.GenderSource,
+		&person.Nationality,
+		&person.NationalityProbability,
+		&person.NationalitySource,
+		&person.EnrichmentStatus,
+		&person.EnrichmentErrors,
+		&person.EnrichedAt,
+		&person.Version,
+		&person.CreatedAt,
+		&person.UpdatedAt,
+		&person.DeletedAt,
+	)
+}
+
+// sortPersons orders query by fields, breaking ties by id so pages are
+// stable. Fields are validated against domain.SortableFields by the service.
+func sortPersons(query sq.SelectBuilder, fields []domain.SortField) sq.SelectBuilder {
+	if len(fields) == 0 {
+		return query
+	}
+
+	for _, field := range fields {
+		if !slices.Contains(domain.SortableFields, field.Field) {
+			continue
+		}
+		if field.Desc {
+			query = query.OrderBy(field.Field + " DESC")
+		} else {
+			query = query.OrderBy(field.Field)
+		}
+	}
+	return query.OrderBy("id")
+}
+
+// missingField holds the condition for a person lacking each enriched
+// attribute.
+var missingField = map[string]sq.Sqlizer{
+	domain.FieldAge:         sq.Eq{"age": 0},
+	domain.FieldGender:      sq.Eq{"gender": ""},
+	domain.FieldNationality: sq.Eq{"nationality": []string{"", "unknown"}},
+}
+
+// personsTable returns the table persons matching filter are read from:
+// persons itself, or with AsOf the versions of persons under the same name.
+func personsTable(filter *domain.PersonFilter) string {
+	if filter.AsOf != nil {
+		return "person_versions AS persons"
+	}
+	return "persons"
+}
+
+// filterPersons adds the conditions of filter, without pagination, to query.
+// With AsOf, query must read from personsTable(filter).
+func filterPersons(query sq.SelectBuilder, person *domain.PersonFilter) sq.SelectBuilder {
+	if person.AsOf != nil {
+		query = query.Where(sq.And{
+			sq.LtOrEq{"valid_from": *person.AsOf},
+			sq.Or{sq.Eq{"valid_to": nil}, sq.Gt{"valid_to": *person.AsOf}},
+		})
+	}
+
+	switch {
+	case person.OnlyDeleted:
+		query = query.Where(sq.NotEq{"deleted_at": nil})
+	case !person.IncludeDeleted:
+		query = query.Where(sq.Eq{"deleted_at": nil})
+	}
+
+	if person.Name != nil {
+		query = query.Where(sq.Or{sq.Eq{"name": *person.Name}, sq.Eq{"name_latin": *person.Name}})
+	}
+
+	if person.Surname != nil {
+		query = query.Where(sq.Or{sq.Eq{"surname": *person.Surname}, sq.Eq{"surname_latin": *person.Surname}})
+	}
+
+	if person.Patronymic != nil {
+		query = query.Where(sq.Or{sq.Eq{"patronymic": *person.Patronymic}, sq.Eq{"patronymic_latin": *person.Patronymic}})
+	}
+
+	if person.MinAge != nil && person.MaxAge != nil {
+		query = query.Where(sq.And{
+			sq.GtOrEq{"age": *person.MinAge},
+			sq.LtOrEq{"age": *person.MaxAge},
+		})
+	} else if person.MinAge != nil {
+		query = query.Where(sq.GtOrEq{"age": *person.MinAge})
+	} else if person.MaxAge != nil {
+		query = query.Where(sq.LtOrEq{"age": *person.MaxAge})
+	}
+
+	if person.MaxAge != nil {
+		query = query.Where(sq.LtOrEq{"age": *person.MaxAge})
+	}
+	if person.Gender != nil {
+		query = query.Where(sq.Eq{"gender": *person.Gender})
+	}
+	if person.Nationality != nil {
+		query = query.Where(sq.Eq{"nationality": *person.Nationality})
+	}
+	if person.MinAgeCount != nil {
+		query = query.Where(sq.GtOrEq{"age_count": *person.MinAgeCount})
+	}
+	if person.MinGenderProbability != nil {
+		query = query.Where(sq.GtOrEq{"gender_probability": *person.MinGenderProbability})
+	}
+	if person.MinGenderCount != nil {
+		query = query.Where(sq.GtOrEq{"gender_count": *person.MinGenderCount})
+	}
+	if person.MinNationalityProbability != nil {
+		query = query.Where(sq.GtOrEq{"nationality_probability": *person.MinNationalityProbability})
+	}
+	if person.EnrichmentStatus != nil {
+		query = query.Where(sq.Eq{"enrichment_status": *person.EnrichmentStatus})
+	}
+	if person.CandidateCountry != nil {
+		minProbability := 0.0
+		if person.MinCandidateProbability != nil {
+			minProbability = *person.MinCandidateProbability
+		}
+		if person.AsOf != nil {
+			query = query.Where(sq.Expr(`EXISTS (
+				SELECT 1 FROM person_nationality_candidate_versions c
+				WHERE c.person_id = persons.id AND c.country_id = ? AND c.probability >= ?
+					AND c.valid_from <= ? AND (c.valid_to IS NULL OR c.valid_to > ?))`,
+				strings.ToUpper(*person.CandidateCountry), minProbability, *person.AsOf, *person.AsOf))
+		} else {
+			query = query.Where(sq.Expr(`EXISTS (
+				SELECT 1 FROM person_nationality_candidates c
+				WHERE c.person_id = persons.id AND c.country_id = ? AND c.probability >= ?)`,
+				strings.ToUpper(*person.CandidateCountry), minProbability))
+		}
+	}
+	if person.StaleSince != nil {
+		query = query.Where(sq.Or{
+			sq.Eq{"enriched_at": nil},
+			sq.Lt{"enriched_at": *person.StaleSince},
+		})
+	}
+	if len(person.MissingFields) > 0 {
+		missing := sq.Or{}
+		for _, field := range person.MissingFields {
+			if cond, ok := missingField[field]; ok {
+				missing = append(missing, cond)
+			}
+		}
+		query = query.Where(missing)
+	}
+
+	return query
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"Effective/internal/domain"
+	"context"
+)
+
+type (
+	actorKey     struct{}
+	requestIDKey struct{}
+)
+
+// WithActor returns a context carrying the actor responsible for the changes
+// made with it.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// WithRequestID returns a context carrying the id of the request the changes
+// made with it belong to.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func actorFrom(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// auditFrom returns who makes the changes with ctx and in which request.
+func auditFrom(ctx context.Context) domain.Audit {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return domain.Audit{Actor: actorFrom(ctx), RequestID: requestID}
+}
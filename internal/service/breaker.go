@@ -0,0 +1,152 @@
+package service
+
+import (
+	"Effective/pkg/logger"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+type BreakerStatus struct {
+	Provider    string       `json:"provider"`
+	State       BreakerState `json:"state"`
+	Failures    int          `json:"failures"`
+	OpenedAt    *time.Time   `json:"opened_at,omitempty"`
+	RetryAfter  *time.Time   `json:"retry_after,omitempty"`
+	LastFailure string       `json:"last_failure,omitempty"`
+}
+
+// circuitBreaker stops calls to a provider after failureThreshold consecutive
+// failures. After openTimeout it lets halfOpenRequests probes through; a
+// successful probe closes it again, a failed one reopens it.
+type circuitBreaker struct {
+	mu     sync.Mutex
+	name   string
+	logger *logger.Logger
+
+	failureThreshold int
+	openTimeout      time.Duration
+	halfOpenRequests int
+
+	state       BreakerState
+	failures    int
+	probes      int
+	openedAt    time.Time
+	lastFailure string
+}
+
+func newCircuitBreaker(name string, logger *logger.Logger, failureThreshold int, openTimeout time.Duration, halfOpenRequests int) *circuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = 1
+	}
+	if halfOpenRequests <= 0 {
+		halfOpenRequests = 1
+	}
+	return &circuitBreaker{
+		name:             name,
+		logger:           logger,
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		halfOpenRequests: halfOpenRequests,
+		state:            BreakerClosed,
+	}
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be
+// followed by exactly one Success, Failure or Release.
+func (b *circuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.transition(BreakerHalfOpen)
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probes >= b.halfOpenRequests {
+			return false
+		}
+		b.probes++
+	}
+	return true
+}
+
+func (b *circuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	if b.state != BreakerClosed {
+		b.transition(BreakerClosed)
+	}
+}
+
+func (b *circuitBreaker) Failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.lastFailure = err.Error()
+
+	if b.state == BreakerHalfOpen || b.failures >= b.failureThreshold {
+		b.openedAt = time.Now()
+		b.transition(BreakerOpen)
+	}
+}
+
+// Release gives back a half-open probe slot for a call whose outcome says
+// nothing about the provider's health, such as a cancelled context.
+func (b *circuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+func (b *circuitBreaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{
+		Provider:    b.name,
+		State:       b.state,
+		Failures:    b.failures,
+		LastFailure: b.lastFailure,
+	}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		retryAfter := b.openedAt.Add(b.openTimeout)
+		status.OpenedAt = &openedAt
+		status.RetryAfter = &retryAfter
+	}
+	return status
+}
+
+func (b *circuitBreaker) transition(state BreakerState) {
+	log := b.logger.Warn
+	if state == BreakerClosed {
+		log = b.logger.Info
+	}
+	log("Circuit breaker state changed",
+		zap.String("provider", b.name),
+		zap.String("from", string(b.state)),
+		zap.String("to", string(state)),
+		zap.Int("failures", b.failures),
+	)
+	b.state = state
+	b.probes = 0
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"Effective/pkg/logger"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	b := newCircuitBreaker("test", &logger.Logger{Logger: zap.NewNop()}, 2, time.Hour, 1)
+	errFail := errors.New("upstream down")
+
+	expect := func(step string, wantState BreakerState, wantAllow bool) {
+		t.Helper()
+		if got := b.Status().State; got != wantState {
+			t.Fatalf("%s: state = %s, want %s", step, got, wantState)
+		}
+		if got := b.Allow(); got != wantAllow {
+			t.Fatalf("%s: Allow() = %v, want %v", step, got, wantAllow)
+		}
+	}
+
+	expect("new breaker", BreakerClosed, true)
+	b.Failure(errFail)
+	expect("below threshold", BreakerClosed, true)
+	b.Failure(errFail)
+	expect("threshold reached", BreakerOpen, false)
+
+	if status := b.Status(); status.RetryAfter == nil || status.LastFailure != errFail.Error() {
+		t.Errorf("open Status() = %+v, want retry_after and last failure", status)
+	}
+
+	// Once the open timeout has passed a single probe is let through.
+	b.openedAt = time.Now().Add(-2 * time.Hour)
+	expect("open timeout passed", BreakerOpen, true)
+	expect("probe in flight", BreakerHalfOpen, false)
+
+	b.Release()
+	expect("probe released", BreakerHalfOpen, true)
+	b.Failure(errFail)
+	expect("failed probe", BreakerOpen, false)
+
+	b.openedAt = time.Now().Add(-2 * time.Hour)
+	expect("second timeout passed", BreakerOpen, true)
+	b.Success()
+	expect("successful probe", BreakerClosed, true)
+
+	if status := b.Status(); status.Failures != 0 || status.OpenedAt != nil {
+		t.Errorf("closed Status() = %+v, want no failures and no opened_at", status)
+	}
+}
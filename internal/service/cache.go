@@ -0,0 +1,257 @@
+package service
+
+import (
+	"Effective/internal/domain"
+	"Effective/internal/repository"
+	"Effective/pkg/logger"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	attributeAge         = domain.FieldAge
+	attributeGender      = domain.FieldGender
+	attributeNationality = domain.FieldNationality
+)
+
+type EnrichmentCacheStore interface {
+	GetEntry(ctx context.Context, name, attribute, countryID string) ([]byte, time.Time, error)
+	SaveEntry(ctx context.Context, name, attribute, countryID string, value []byte, expiresAt time.Time) error
+	DeleteByName(ctx context.Context, name string) (int64, error)
+	DeleteAll(ctx context.Context) (int64, error)
+}
+
+type CacheStats struct {
+	MemoryHits int64 `json:"memory_hits"`
+	StoreHits  int64 `json:"store_hits"`
+	Misses     int64 `json:"misses"`
+	MemorySize int   `json:"memory_size"`
+}
+
+// CachedEnricher is an EnricherService decorator that serves repeated names
+// from an in-process LRU backed by the name_enrichment_cache table. Only
+// predictions whose source passes cacheable are kept, so a fallback answer
+// given while a remote provider was down is asked again next time.
+type CachedEnricher struct {
+	next      EnricherService
+	cacheable func(source string) bool
+	store     EnrichmentCacheStore
+	memory    *lruCache
+	ttl       time.Duration
+	logger    *logger.Logger
+
+	memoryHits atomic.Int64
+	storeHits  atomic.Int64
+	misses     atomic.Int64
+}
+
+func NewCachedEnricher(next EnricherService, cacheable func(source string) bool, store EnrichmentCacheStore, logger *logger.Logger, size int, ttl time.Duration) *CachedEnricher {
+	return &CachedEnricher{
+		next:      next,
+		cacheable: cacheable,
+		store:     store,
+		memory:    newLRUCache(size),
+		ttl:       ttl,
+		logger:    logger,
+	}
+}
+
+func (c *CachedEnricher) GetAgeByName(ctx context.Context, name, countryID string) (domain.AgePrediction, error) {
+	return cached(ctx, c, name, attributeAge, countryID, func() (domain.AgePrediction, error) {
+		return c.next.GetAgeByName(ctx, name, countryID)
+	})
+}
+
+func (c *CachedEnricher) GetGenderByName(ctx context.Context, name, countryID string) (domain.GenderPrediction, error) {
+	return cached(ctx, c, name, attributeGender, countryID, func() (domain.GenderPrediction, error) {
+		return c.next.GetGenderByName(ctx, name, countryID)
+	})
+}
+
+func (c *CachedEnricher) GetNationalityByName(ctx context.Context, name string) (domain.NationalityPrediction, error) {
+	return cached(ctx, c, name, attributeNationality, "", func() (domain.NationalityPrediction, error) {
+		return c.next.GetNationalityByName(ctx, name)
+	})
+}
+
+func (c *CachedEnricher) GetAgesByNames(ctx context.Context, names []string, countryID string) (map[string]domain.AgePrediction, error) {
+	return cachedBatch(ctx, c, names, attributeAge, countryID, func(missing []string) (map[string]domain.AgePrediction, error) {
+		return c.next.GetAgesByNames(ctx, missing, countryID)
+	})
+}
+
+func (c *CachedEnricher) GetGendersByNames(ctx context.Context, names []string, countryID string) (map[string]domain.GenderPrediction, error) {
+	return cachedBatch(ctx, c, names, attributeGender, countryID, func(missing []string) (map[string]domain.GenderPrediction, error) {
+		return c.next.GetGendersByNames(ctx, missing, countryID)
+	})
+}
+
+func (c *CachedEnricher) GetNationalitiesByNames(ctx context.Context, names []string) (map[string]domain.NationalityPrediction, error) {
+	return cachedBatch(ctx, c, names, attributeNationality, "", func(missing []string) (map[string]domain.NationalityPrediction, error) {
+		return c.next.GetNationalitiesByNames(ctx, missing)
+	})
+}
+
+func (c *CachedEnricher) Stats() CacheStats {
+	return CacheStats{
+		MemoryHits: c.memoryHits.Load(),
+		StoreHits:  c.storeHits.Load(),
+		Misses:     c.misses.Load(),
+		MemorySize: c.memory.Len(),
+	}
+}
+
+func (c *CachedEnricher) Invalidate(ctx context.Context, name string) (int64, error) {
+	key := cacheName(name)
+	c.memory.DeleteFunc(func(k string) bool {
+		parts := strings.SplitN(k, ":", 3)
+		return len(parts) == 3 && parts[2] == key
+	})
+
+	removed, err := c.store.DeleteByName(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to invalidate cache for name: %w", err)
+	}
+
+	c.logger.Info("Enrichment cache invalidated", zap.String("name", key), zap.Int64("removed", removed))
+	return removed, nil
+}
+
+func (c *CachedEnricher) InvalidateAll(ctx context.Context) (int64, error) {
+	c.memory.Purge()
+
+	removed, err := c.store.DeleteAll(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to invalidate cache: %w", err)
+	}
+
+	c.logger.Info("Enrichment cache purged", zap.Int64("removed", removed))
+	return removed, nil
+}
+
+func cached[T any](ctx context.Context, c *CachedEnricher, name, attribute, countryID string, fetch func() (T, error)) (T, error) {
+	if value, ok := lookup[T](ctx, c, name, attribute, countryID); ok {
+		return value, nil
+	}
+
+	c.misses.Add(1)
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+
+	remember(ctx, c, name, attribute, countryID, value)
+	return value, nil
+}
+
+func cachedBatch[T any](ctx context.Context, c *CachedEnricher, names []string, attribute, countryID string, fetch func(missing []string) (map[string]T, error)) (map[string]T, error) {
+	results := make(map[string]T, len(names))
+	missing := make([]string, 0, len(names))
+
+	for _, name := range uniqueNames(names) {
+		if value, ok := lookup[T](ctx, c, name, attribute, countryID); ok {
+			results[name] = value
+			continue
+		}
+		missing = append(missing, name)
+	}
+
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	c.misses.Add(int64(len(missing)))
+	fetched, err := fetch(missing)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range fetched {
+		remember(ctx, c, name, attribute, countryID, value)
+		results[name] = value
+	}
+	return results, nil
+}
+
+func lookup[T any](ctx context.Context, c *CachedEnricher, name, attribute, countryID string) (T, bool) {
+	var zero T
+	key := cacheName(name)
+	memoryKey := cacheKey(attribute, countryID, key)
+
+	if value, ok := c.memory.Get(memoryKey); ok {
+		if typed, ok := value.(T); ok {
+			c.memoryHits.Add(1)
+			return typed, true
+		}
+	}
+
+	raw, expiresAt, err := c.store.GetEntry(ctx, key, attribute, countryID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrCacheEntryNotFound) {
+			c.logger.Warn("Failed to read enrichment cache", zap.String("name", key), zap.Error(err))
+		}
+		return zero, false
+	}
+
+	var value T
+	if err := json.Unmarshal(raw, &value); err != nil {
+		c.logger.Warn("Invalid enrichment cache entry", zap.String("name", key), zap.String("attribute", attribute))
+		return zero, false
+	}
+	if !c.cacheable(predictionSource(value)) {
+		return zero, false
+	}
+
+	c.storeHits.Add(1)
+	c.memory.Set(memoryKey, value, expiresAt)
+	return value, true
+}
+
+func remember[T any](ctx context.Context, c *CachedEnricher, name, attribute, countryID string, value T) {
+	if !c.cacheable(predictionSource(value)) {
+		return
+	}
+
+	key := cacheName(name)
+	expiresAt := time.Now().Add(c.ttl)
+	c.memory.Set(cacheKey(attribute, countryID, key), value, expiresAt)
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		c.logger.Warn("Failed to encode enrichment cache entry", zap.String("name", key), zap.Error(err))
+		return
+	}
+	if err := c.store.SaveEntry(ctx, key, attribute, countryID, raw, expiresAt); err != nil {
+		c.logger.Warn("Failed to write enrichment cache", zap.String("name", key), zap.Error(err))
+	}
+}
+
+func predictionSource(value any) string {
+	switch v := value.(type) {
+	case domain.AgePrediction:
+		return v.Source
+	case domain.GenderPrediction:
+		return v.Source
+	case domain.NationalityPrediction:
+		return v.Source
+	default:
+		return ""
+	}
+}
+
+func cacheName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// cacheKey builds the in-memory key. The name comes last, so it may itself
+// contain colons; Invalidate splits off the attribute and country to match it.
+func cacheKey(attribute, countryID, name string) string {
+	return attribute + ":" + countryID + ":" + name
+}
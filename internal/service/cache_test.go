@@ -0,0 +1,48 @@
+package service
+
+import (
+	"Effective/pkg/logger"
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type nopCacheStore struct{}
+
+func (nopCacheStore) GetEntry(context.Context, string, string, string) ([]byte, time.Time, error) {
+	return nil, time.Time{}, nil
+}
+
+func (nopCacheStore) SaveEntry(context.Context, string, string, string, []byte, time.Time) error {
+	return nil
+}
+
+func (nopCacheStore) DeleteByName(context.Context, string) (int64, error) { return 0, nil }
+
+func (nopCacheStore) DeleteAll(context.Context) (int64, error) { return 0, nil }
+
+func TestCachedEnricherInvalidate(t *testing.T) {
+	c := NewCachedEnricher(nil, nil, nopCacheStore{}, &logger.Logger{Logger: zap.NewNop()}, 10, time.Hour)
+	expires := time.Now().Add(time.Hour)
+
+	keys := map[string]bool{
+		cacheKey(attributeAge, "", "anna"):         false,
+		cacheKey(attributeGender, "RU", "anna"):    false,
+		cacheKey(attributeAge, "", "x:anna"):       true,
+		cacheKey(attributeNationality, "", "mari"): true,
+	}
+	for key := range keys {
+		c.memory.Set(key, 1, expires)
+	}
+
+	if _, err := c.Invalidate(context.Background(), " Anna "); err != nil {
+		t.Fatalf("Invalidate() error = %v", err)
+	}
+	for key, wantKept := range keys {
+		if _, ok := c.memory.Get(key); ok != wantKept {
+			t.Errorf("key %q kept = %v, want %v", key, ok, wantKept)
+		}
+	}
+}
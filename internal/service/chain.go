@@ -0,0 +1,284 @@
+package service
+
+import (
+	"Effective/internal/domain"
+	"Effective/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type chainLink[P any] struct {
+	name           string
+	provider       P
+	minProbability float64
+	minCount       int
+}
+
+// ProviderChain is an EnricherService that asks an ordered list of providers
+// per attribute and falls back to the next one when a provider fails or its
+// answer is below that provider's confidence thresholds. Every prediction
+// records the provider that produced it in Source.
+type ProviderChain struct {
+	age         []chainLink[AgeProvider]
+	gender      []chainLink[GenderProvider]
+	nationality []chainLink[NationalityProvider]
+	datasets    map[string]*DatasetProvider
+	remote      map[string]bool
+	logger      *logger.Logger
+}
+
+type ProviderChainStatus struct {
+	Age         []string        `json:"age"`
+	Gender      []string        `json:"gender"`
+	Nationality []string        `json:"nationality"`
+	Datasets    []DatasetStatus `json:"datasets"`
+}
+
+// IsRemote reports whether source names an HTTP provider of the chain.
+// Dataset and static providers answer locally, so only remote answers are
+// worth caching.
+func (c *ProviderChain) IsRemote(source string) bool {
+	return c.remote[source]
+}
+
+func (c *ProviderChain) GetAgeByName(ctx context.Context, name, countryID string) (domain.AgePrediction, error) {
+	return firstConfident(ctx, c, attributeAge, c.age,
+		func(p AgeProvider) (domain.AgePrediction, error) { return p.GetAgeByName(ctx, name, countryID) },
+		ageConfident, tagAge)
+}
+
+func (c *ProviderChain) GetGenderByName(ctx context.Context, name, countryID string) (domain.GenderPrediction, error) {
+	return firstConfident(ctx, c, attributeGender, c.gender,
+		func(p GenderProvider) (domain.GenderPrediction, error) {
+			return p.GetGenderByName(ctx, name, countryID)
+		},
+		genderConfident, tagGender)
+}
+
+func (c *ProviderChain) GetNationalityByName(ctx context.Context, name string) (domain.NationalityPrediction, error) {
+	return firstConfident(ctx, c, attributeNationality, c.nationality,
+		func(p NationalityProvider) (domain.NationalityPrediction, error) {
+			return p.GetNationalityByName(ctx, name)
+		},
+		nationalityConfident, tagNationality)
+}
+
+func (c *ProviderChain) GetAgesByNames(ctx context.Context, names []string, countryID string) (map[string]domain.AgePrediction, error) {
+	return firstConfidentBatch(ctx, c, attributeAge, c.age, names,
+		func(p AgeProvider, pending []string) (map[string]domain.AgePrediction, error) {
+			return p.GetAgesByNames(ctx, pending, countryID)
+		},
+		ageConfident, tagAge)
+}
+
+func (c *ProviderChain) GetGendersByNames(ctx context.Context, names []string, countryID string) (map[string]domain.GenderPrediction, error) {
+	return firstConfidentBatch(ctx, c, attributeGender, c.gender, names,
+		func(p GenderProvider, pending []string) (map[string]domain.GenderPrediction, error) {
+			return p.GetGendersByNames(ctx, pending, countryID)
+		},
+		genderConfident, tagGender)
+}
+
+func (c *ProviderChain) GetNationalitiesByNames(ctx context.Context, names []string) (map[string]domain.NationalityPrediction, error) {
+	return firstConfidentBatch(ctx, c, attributeNationality, c.nationality, names,
+		func(p NationalityProvider, pending []string) (map[string]domain.NationalityPrediction, error) {
+			return p.GetNationalitiesByNames(ctx, pending)
+		},
+		nationalityConfident, tagNationality)
+}
+
+func (c *ProviderChain) Status() ProviderChainStatus {
+	return ProviderChainStatus{
+		Age:         linkNames(c.age),
+		Gender:      linkNames(c.gender),
+		Nationality: linkNames(c.nationality),
+		Datasets:    c.datasetStatus(),
+	}
+}
+
+// ReloadDatasets re-reads every dataset file. A dataset that fails to load
+// keeps serving its previous contents.
+func (c *ProviderChain) ReloadDatasets() ([]DatasetStatus, error) {
+	var errs []error
+	for _, dataset := range c.datasets {
+		if err := dataset.Reload(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		c.logger.Info("Enrichment dataset reloaded", zap.String("path", dataset.path), zap.Int("names", dataset.Status().Names))
+	}
+	return c.datasetStatus(), errors.Join(errs...)
+}
+
+func (c *ProviderChain) datasetStatus() []DatasetStatus {
+	statuses := make([]DatasetStatus, 0, len(c.datasets))
+	for _, dataset := range c.datasets {
+		statuses = append(statuses, dataset.Status())
+	}
+	slices.SortFunc(statuses, func(a, b DatasetStatus) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return statuses
+}
+
+// firstConfident returns the first prediction that passes its provider's
+// thresholds. If none does, the first prediction obtained at all is returned;
+// if every provider failed, their joined errors are. An answer that breaks
+// the rules of its field counts as a failure.
+func firstConfident[P, T any](
+	ctx context.Context,
+	c *ProviderChain,
+	attribute string,
+	links []chainLink[P],
+	get func(P) (T, error),
+	confident func(chainLink[P], T) bool,
+	tag func(T, string) T,
+) (T, error) {
+	var (
+		fallback *T
+		errs     []error
+	)
+
+	for _, link := range links {
+		if ctx.Err() != nil {
+			break
+		}
+
+		value, err := get(link.provider)
+		if err == nil {
+			err = validatePrediction(value)
+		}
+		if err != nil {
+			c.logger.Warn("Enrichment provider failed",
+				zap.String("attribute", attribute), zap.String("provider", link.name), zap.Error(err))
+			errs = append(errs, fmt.Errorf("%s: %w", link.name, err))
+			continue
+		}
+
+		value = tag(value, link.name)
+		if confident(link, value) {
+			return value, nil
+		}
+
+		c.logger.Info("Enrichment provider below confidence threshold",
+			zap.String("attribute", attribute), zap.String("provider", link.name))
+		if fallback == nil {
+			fallback = &value
+		}
+	}
+
+	if fallback != nil {
+		return *fallback, nil
+	}
+
+	var zero T
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return zero, fmt.Errorf("no %s provider succeeded: %w", attribute, errors.Join(errs...))
+}
+
+// firstConfidentBatch is firstConfident for many names: each provider is only
+// asked for the names that no earlier provider answered confidently.
+func firstConfidentBatch[P, T any](
+	ctx context.Context,
+	c *ProviderChain,
+	attribute string,
+	links []chainLink[P],
+	names []string,
+	get func(P, []string) (map[string]T, error),
+	confident func(chainLink[P], T) bool,
+	tag func(T, string) T,
+) (map[string]T, error) {
+	pending := uniqueNames(names)
+	results := make(map[string]T, len(pending))
+	fallback := make(map[string]T)
+	var errs []error
+
+	for _, link := range links {
+		if len(pending) == 0 || ctx.Err() != nil {
+			break
+		}
+
+		values, err := get(link.provider, pending)
+		if err != nil {
+			c.logger.Warn("Enrichment provider failed",
+				zap.String("attribute", attribute), zap.String("provider", link.name), zap.Error(err))
+			errs = append(errs, fmt.Errorf("%s: %w", link.name, err))
+			continue
+		}
+
+		remaining := make([]string, 0, len(pending))
+		for _, name := range pending {
+			value, ok := values[name]
+			if err := validatePrediction(value); ok && err != nil {
+				c.logger.Warn("Enrichment provider gave an invalid answer",
+					zap.String("attribute", attribute), zap.String("provider", link.name), zap.Error(err))
+				ok = false
+			}
+			if ok {
+				value = tag(value, link.name)
+				if confident(link, value) {
+					results[name] = value
+					continue
+				}
+				if _, seen := fallback[name]; !seen {
+					fallback[name] = value
+				}
+			}
+			remaining = append(remaining, name)
+		}
+		pending = remaining
+	}
+
+	for _, name := range pending {
+		if value, ok := fallback[name]; ok {
+			results[name] = value
+		}
+	}
+
+	if len(results) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("no %s provider succeeded: %w", attribute, errors.Join(errs...))
+	}
+	return results, nil
+}
+
+func ageConfident(link chainLink[AgeProvider], p domain.AgePrediction) bool {
+	return p.Age > 0 && p.Count >= link.minCount
+}
+
+func genderConfident(link chainLink[GenderProvider], p domain.GenderPrediction) bool {
+	return p.Gender != "" && p.Probability >= link.minProbability && p.Count >= link.minCount
+}
+
+func nationalityConfident(link chainLink[NationalityProvider], p domain.NationalityPrediction) bool {
+	return p.CountryID != "" && p.CountryID != unknownCountry && p.Probability >= link.minProbability
+}
+
+func tagAge(p domain.AgePrediction, source string) domain.AgePrediction {
+	p.Source = source
+	return p
+}
+
+func tagGender(p domain.GenderPrediction, source string) domain.GenderPrediction {
+	p.Source = source
+	return p
+}
+
+func tagNationality(p domain.NationalityPrediction, source string) domain.NationalityPrediction {
+	p.Source = source
+	return p
+}
+
+func linkNames[P any](links []chainLink[P]) []string {
+	names := make([]string, 0, len(links))
+	for _, link := range links {
+		names = append(names, link.name)
+	}
+	return names
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"Effective/internal/domain"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type datasetRecord struct {
+	Name              string               `json:"name"`
+	Age               int                  `json:"age"`
+	AgeCount          int                  `json:"age_count"`
+	Gender            string               `json:"gender"`
+	GenderProbability float64              `json:"gender_probability"`
+	GenderCount       int                  `json:"gender_count"`
+	Countries         []nationalizeCountry `json:"countries"`
+}
+
+type DatasetStatus struct {
+	Path     string    `json:"path"`
+	Names    int       `json:"names"`
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+// DatasetProvider answers enrichment requests from a local name dataset held
+// in memory. The file is either a JSON array of records or a CSV with the
+// header
+//
+//	name,age,age_count,gender,gender_probability,gender_count,country_id,country_probability
+//
+// where a name may repeat on several rows to list more candidate countries.
+// Reload swaps in a fresh copy of the file without blocking readers.
+type DatasetProvider struct {
+	path string
+
+	mu       sync.RWMutex
+	records  map[string]*datasetRecord
+	loadedAt time.Time
+}
+
+func NewDatasetProvider(path string) (*DatasetProvider, error) {
+	p := &DatasetProvider{path: path}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *DatasetProvider) Reload() error {
+	records, err := loadDataset(p.path)
+	if err != nil {
+		return fmt.Errorf("failed to load dataset %s: %w", p.path, err)
+	}
+
+	p.mu.Lock()
+	p.records = records
+	p.loadedAt = time.Now()
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *DatasetProvider) Status() DatasetStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return DatasetStatus{Path: p.path, Names: len(p.records), LoadedAt: p.loadedAt}
+}
+
+func (p *DatasetProvider) GetAgeByName(_ context.Context, name, _ string) (domain.AgePrediction, error) {
+	record, err := p.record(name)
+	if err != nil {
+		return domain.AgePrediction{}, err
+	}
+	return domain.AgePrediction{Age: record.Age, Count: record.AgeCount}, nil
+}
+
+func (p *DatasetProvider) GetGenderByName(_ context.Context, name, _ string) (domain.GenderPrediction, error) {
+	record, err := p.record(name)
+	if err != nil {
+		return domain.GenderPrediction{}, err
+	}
+	return domain.GenderPrediction{
+		Gender:      record.Gender,
+		Probability: record.GenderProbability,
+		Count:       record.GenderCount,
+	}, nil
+}
+
+func (p *DatasetProvider) GetNationalityByName(_ context.Context, name string) (domain.NationalityPrediction, error) {
+	record, err := p.record(name)
+	if err != nil {
+		return domain.NationalityPrediction{}, err
+	}
+	if len(record.Countries) == 0 {
+		return domain.NationalityPrediction{CountryID: unknownCountry}, nil
+	}
+
+	candidates := make([]domain.NationalityCandidate, 0, len(record.Countries))
+	for _, country := range record.Countries {
+		candidates = append(candidates, domain.NationalityCandidate{
+			CountryID:   country.CountryID,
+			Probability: country.Probability,
+		})
+	}
+	return domain.NationalityPrediction{
+		CountryID:   candidates[0].CountryID,
+		Probability: candidates[0].Probability,
+		Candidates:  candidates,
+	}, nil
+}
+
+func (p *DatasetProvider) GetAgesByNames(ctx context.Context, names []string, countryID string) (map[string]domain.AgePrediction, error) {
+	return datasetBatch(names, func(name string) (domain.AgePrediction, error) {
+		return p.GetAgeByName(ctx, name, countryID)
+	})
+}
+
+func (p *DatasetProvider) GetGendersByNames(ctx context.Context, names []string, countryID string) (map[string]domain.GenderPrediction, error) {
+	return datasetBatch(names, func(name string) (domain.GenderPrediction, error) {
+		return p.GetGenderByName(ctx, name, countryID)
+	})
+}
+
+func (p *DatasetProvider) GetNationalitiesByNames(ctx context.Context, names []string) (map[string]domain.NationalityPrediction, error) {
+	return datasetBatch(names, func(name string) (domain.NationalityPrediction, error) {
+		return p.GetNationalityByName(ctx, name)
+	})
+}
+
+func (p *DatasetProvider) record(name string) (*datasetRecord, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	record, ok := p.records[datasetKey(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNameNotFound, name)
+	}
+	return record, nil
+}
+
+// datasetBatch leaves names missing from the dataset out of the result, so a
+// provider chain asks the next provider for them.
+func datasetBatch[T any](names []string, get func(name string) (T, error)) (map[string]T, error) {
+	results := make(map[string]T, len(names))
+	for _, name := range names {
+		value, err := get(name)
+		if err != nil {
+			if errors.Is(err, ErrNameNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		results[name] = value
+	}
+	return results, nil
+}
+
+func loadDataset(path string) (map[string]*datasetRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []datasetRecord
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err := json.NewDecoder(file).Decode(&records); err != nil {
+			return nil, fmt.Errorf("json decode failed: %w", err)
+		}
+	case ".csv":
+		records, err = readDatasetCSV(file)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported dataset format %q", filepath.Ext(path))
+	}
+
+	byName := make(map[string]*datasetRecord, len(records))
+	for i := range records {
+		record := &records[i]
+		key := datasetKey(record.Name)
+		if key == "" {
+			continue
+		}
+		if existing, ok := byName[key]; ok {
+			existing.Countries = append(existing.Countries, record.Countries...)
+			continue
+		}
+		byName[key] = record
+	}
+
+	for _, record := range byName {
+		for i := range record.Countries {
+			record.Countries[i].CountryID = strings.ToUpper(record.Countries[i].CountryID)
+		}
+		sort.SliceStable(record.Countries, func(i, j int) bool {
+			return record.Countries[i].Probability > record.Countries[j].Probability
+		})
+	}
+	return byName, nil
+}
+
+func readDatasetCSV(r io.Reader) ([]datasetRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv header read failed: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.TrimSpace(strings.ToLower(column))] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New("csv header has no name column")
+	}
+
+	var records []datasetRecord
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv read failed: %w", err)
+		}
+
+		field := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		record := datasetRecord{Name: field("name"), Gender: field("gender")}
+		if record.Age, err = atoiOrZero(field("age")); err != nil {
+			return nil, fmt.Errorf("line %d: age: %w", line, err)
+		}
+		if record.AgeCount, err = atoiOrZero(field("age_count")); err != nil {
+			return nil, fmt.Errorf("line %d: age_count: %w", line, err)
+		}
+		if record.GenderProbability, err = atofOrZero(field("gender_probability")); err != nil {
+			return nil, fmt.Errorf("line %d: gender_probability: %w", line, err)
+		}
+		if record.GenderCount, err = atoiOrZero(field("gender_count")); err != nil {
+			return nil, fmt.Errorf("line %d: gender_count: %w", line, err)
+		}
+		if countryID := field("country_id"); countryID != "" {
+			probability, err := atofOrZero(field("country_probability"))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: country_probability: %w", line, err)
+			}
+			record.Countries = []nationalizeCountry{{CountryID: countryID, Probability: probability}}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func atoiOrZero(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func atofOrZero(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+func datasetKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testDataset = `name,age,age_count,gender,gender_probability,gender_count,country_id,country_probability
+Dmitriy,42,1000,male,0.99,900,ua,0.2
+dmitriy,,,,,,RU,0.7
+Anna,31,500,female,0.98,400,,
+`
+
+func newTestDataset(t *testing.T, name, content string) *DatasetProvider {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewDatasetProvider(path)
+	if err != nil {
+		t.Fatalf("NewDatasetProvider() error = %v", err)
+	}
+	return p
+}
+
+func TestDatasetProviderLookup(t *testing.T) {
+	p := newTestDataset(t, "names.csv", testDataset)
+	ctx := context.Background()
+
+	if got := p.Status().Names; got != 2 {
+		t.Errorf("Status().Names = %d, want 2", got)
+	}
+
+	age, err := p.GetAgeByName(ctx, " DMITRIY ", "")
+	if err != nil || age.Age != 42 || age.Count != 1000 {
+		t.Errorf("GetAgeByName() = %+v, %v, want 42 from 1000", age, err)
+	}
+
+	gender, err := p.GetGenderByName(ctx, "anna", "")
+	if err != nil || gender.Gender != "female" || gender.Probability != 0.98 {
+		t.Errorf("GetGenderByName() = %+v, %v, want female at 0.98", gender, err)
+	}
+
+	nationality, err := p.GetNationalityByName(ctx, "Dmitriy")
+	if err != nil {
+		t.Fatalf("GetNationalityByName() error = %v", err)
+	}
+	if nationality.CountryID != "RU" || len(nationality.Candidates) != 2 || nationality.Candidates[1].CountryID != "UA" {
+		t.Errorf("GetNationalityByName() = %+v, want RU then UA", nationality)
+	}
+
+	if nationality, err := p.GetNationalityByName(ctx, "Anna"); err != nil || nationality.CountryID != unknownCountry {
+		t.Errorf("GetNationalityByName(Anna) = %+v, %v, want %s", nationality, err, unknownCountry)
+	}
+
+	if _, err := p.GetAgeByName(ctx, "Nobody", ""); !errors.Is(err, ErrNameNotFound) {
+		t.Errorf("GetAgeByName(Nobody) error = %v, want %v", err, ErrNameNotFound)
+	}
+}
+
+func TestDatasetProviderBatchSkipsMissing(t *testing.T) {
+	p := newTestDataset(t, "names.json", `[{"name":"Olga","age":50,"age_count":10}]`)
+
+	ages, err := p.GetAgesByNames(context.Background(), []string{"Olga", "Nobody"}, "")
+	if err != nil {
+		t.Fatalf("GetAgesByNames() error = %v", err)
+	}
+	if len(ages) != 1 || ages["Olga"].Age != 50 {
+		t.Errorf("GetAgesByNames() = %+v, want only Olga", ages)
+	}
+}
@@ -2,120 +2,255 @@ package service
 
 import (
 	"Effective/config"
+	"Effective/internal/domain"
+	"Effective/pkg/cassette"
 	"Effective/pkg/logger"
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
 
 	"go.uber.org/zap"
 )
 
 const (
-	keyName = "name"
+	keyName      = "name"
+	keyNameBatch = "name[]"
+	keyCountryID = "country_id"
+	maxBatchSize = 10
+
+	unknownCountry = "unknown"
 )
 
 type Enricher struct {
 	client *http.Client
 	logger *logger.Logger
 	cfg    *config.Config
+
+	mu       sync.Mutex
+	limits   map[string]*rateLimit
+	breakers map[string]*circuitBreaker
 }
 
 func NewEnricher(logger *logger.Logger, cfg *config.Config) *Enricher {
-	return &Enricher{
-		client: &http.Client{Timeout: 5 * time.Second},
-		logger: logger,
-		cfg:    cfg,
+	e := &Enricher{
+		client: &http.Client{
+			Timeout:   cfg.Enricher.Timeout,
+			Transport: cassette.NewTransport(cfg.Enricher.HTTPMode, cfg.Enricher.CassetteDir, nil),
+		},
+		logger:   logger,
+		cfg:      cfg,
+		limits:   make(map[string]*rateLimit),
+		breakers: make(map[string]*circuitBreaker),
 	}
+	for _, endpoint := range []string{cfg.APIUrl.AgifyUrl, cfg.APIUrl.GenderizeUrl, cfg.APIUrl.NationalizeUrl} {
+		e.breaker(endpoint)
+	}
+	return e
 }
 
-func (e *Enricher) GetAgeByName(ctx context.Context, name string) (int, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", e.cfg.APIUrl.AgifyUrl, nil)
-	if err != nil {
-		return 0, fmt.Errorf("request failed: %w", err)
+// BreakerStatus returns the circuit breaker state of every provider URL.
+func (e *Enricher) BreakerStatus() []BreakerStatus {
+	e.mu.Lock()
+	breakers := make([]*circuitBreaker, 0, len(e.breakers))
+	for _, b := range e.breakers {
+		breakers = append(breakers, b)
 	}
+	e.mu.Unlock()
 
-	query := req.URL.Query()
-	query.Add(keyName, name)
-	req.URL.RawQuery = query.Encode()
-	e.logger.Debug("Request URL", zap.String("url", req.URL.String()))
-
-	resp, err := e.client.Do(req)
-	if err != nil {
-		return 0, fmt.Errorf("request failed: %w", err)
+	statuses := make([]BreakerStatus, 0, len(breakers))
+	for _, b := range breakers {
+		statuses = append(statuses, b.Status())
 	}
+	slices.SortFunc(statuses, func(a, b BreakerStatus) int {
+		return strings.Compare(a.Provider, b.Provider)
+	})
+	return statuses
+}
 
-	defer resp.Body.Close()
+func (e *Enricher) breaker(endpoint string) *circuitBreaker {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("unexpected status: %s", resp.Status)
+	b, ok := e.breakers[endpoint]
+	if !ok {
+		cfg := e.cfg.Enricher
+		b = newCircuitBreaker(endpoint, e.logger, cfg.BreakerFailureThreshold, cfg.BreakerOpenTimeout, cfg.BreakerHalfOpenRequests)
+		e.breakers[endpoint] = b
 	}
+	return b
+}
+
+func (e *Enricher) GetAgeByName(ctx context.Context, name, countryID string) (domain.AgePrediction, error) {
+	return e.endpoint(e.cfg.APIUrl.AgifyUrl).GetAgeByName(ctx, name, countryID)
+}
+
+func (e *Enricher) GetGenderByName(ctx context.Context, name, countryID string) (domain.GenderPrediction, error) {
+	return e.endpoint(e.cfg.APIUrl.GenderizeUrl).GetGenderByName(ctx, name, countryID)
+}
+
+func (e *Enricher) GetNationalityByName(ctx context.Context, name string) (domain.NationalityPrediction, error) {
+	return e.endpoint(e.cfg.APIUrl.NationalizeUrl).GetNationalityByName(ctx, name)
+}
 
+func (e *Enricher) GetAgesByNames(ctx context.Context, names []string, countryID string) (map[string]domain.AgePrediction, error) {
+	return e.endpoint(e.cfg.APIUrl.AgifyUrl).GetAgesByNames(ctx, names, countryID)
+}
+
+func (e *Enricher) GetGendersByNames(ctx context.Context, names []string, countryID string) (map[string]domain.GenderPrediction, error) {
+	return e.endpoint(e.cfg.APIUrl.GenderizeUrl).GetGendersByNames(ctx, names, countryID)
+}
+
+func (e *Enricher) GetNationalitiesByNames(ctx context.Context, names []string) (map[string]domain.NationalityPrediction, error) {
+	return e.endpoint(e.cfg.APIUrl.NationalizeUrl).GetNationalitiesByNames(ctx, names)
+}
+
+// endpoint returns a provider that sends agify, genderize or nationalize
+// style requests to url through this Enricher's retries and breakers.
+func (e *Enricher) endpoint(url string) *httpProvider {
+	return &httpProvider{enricher: e, url: url}
+}
+
+type httpProvider struct {
+	enricher *Enricher
+	url      string
+}
+
+func (p *httpProvider) GetAgeByName(ctx context.Context, name, countryID string) (domain.AgePrediction, error) {
 	result := &apiAgeResponse{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return 0, fmt.Errorf("json decode failed: %w", err)
+	if err := p.enricher.getJSON(ctx, p.url, withCountry(url.Values{keyName: {name}}, countryID), result); err != nil {
+		return domain.AgePrediction{}, err
 	}
-	e.logger.Debug("Response data from api", zap.Int("Age", result.Age))
-	return result.Age, nil
+	p.enricher.logger.Debug("Response data from api", zap.Int("Age", result.Age), zap.Int("Count", result.Count))
+	return result.prediction(), nil
 }
 
-func (e *Enricher) GetGenderByName(ctx context.Context, name string) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", e.cfg.APIUrl.GenderizeUrl, nil)
-	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+func (p *httpProvider) GetGenderByName(ctx context.Context, name, countryID string) (domain.GenderPrediction, error) {
+	result := &apiGenderResponse{}
+	if err := p.enricher.getJSON(ctx, p.url, withCountry(url.Values{keyName: {name}}, countryID), result); err != nil {
+		return domain.GenderPrediction{}, err
 	}
+	p.enricher.logger.Debug("Response data from api", zap.String("Gender", result.Gender), zap.Float64("Probability", result.Probability))
+	return result.prediction(), nil
+}
 
-	query := req.URL.Query()
-	query.Add(keyName, name)
-	req.URL.RawQuery = query.Encode()
+func (p *httpProvider) GetNationalityByName(ctx context.Context, name string) (domain.NationalityPrediction, error) {
+	result := &apiNationalityResponse{}
+	if err := p.enricher.getJSON(ctx, p.url, url.Values{keyName: {name}}, result); err != nil {
+		return domain.NationalityPrediction{}, err
+	}
+
+	nationality := p.enricher.topCountry(result)
+	p.enricher.logger.Debug("Response data from api", zap.String("Nationality", nationality.CountryID), zap.Float64("Probability", nationality.Probability))
+	return nationality, nil
+}
 
-	resp, err := e.client.Do(req)
+func (p *httpProvider) GetAgesByNames(ctx context.Context, names []string, countryID string) (map[string]domain.AgePrediction, error) {
+	results, err := fetchBatch[apiAgeResponse](ctx, p.enricher, p.url, names, countryID)
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status: %s", resp.Status)
+	ages := make(map[string]domain.AgePrediction, len(results))
+	for name, result := range results {
+		ages[name] = result.prediction()
 	}
+	return ages, nil
+}
 
-	result := &apiGenderResponse{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("json decode failed: %w", err)
+func (p *httpProvider) GetGendersByNames(ctx context.Context, names []string, countryID string) (map[string]domain.GenderPrediction, error) {
+	results, err := fetchBatch[apiGenderResponse](ctx, p.enricher, p.url, names, countryID)
+	if err != nil {
+		return nil, err
 	}
-	e.logger.Debug("Response data from api", zap.String("Gender", result.Gender))
-	return result.Gender, nil
+
+	genders := make(map[string]domain.GenderPrediction, len(results))
+	for name, result := range results {
+		genders[name] = result.prediction()
+	}
+	return genders, nil
 }
 
-func (e *Enricher) GetNationalityByName(ctx context.Context, name string) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", e.cfg.APIUrl.NationalizeUrl, nil)
+func (p *httpProvider) GetNationalitiesByNames(ctx context.Context, names []string) (map[string]domain.NationalityPrediction, error) {
+	results, err := fetchBatch[apiNationalityResponse](ctx, p.enricher, p.url, names, "")
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
 
-	query := req.URL.Query()
-	query.Add(keyName, name)
-	req.URL.RawQuery = query.Encode()
+	nationalities := make(map[string]domain.NationalityPrediction, len(results))
+	for name, result := range results {
+		nationalities[name] = p.enricher.topCountry(&result)
+	}
+	return nationalities, nil
+}
 
-	resp, err := e.client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+func (e *Enricher) topCountry(result *apiNationalityResponse) domain.NationalityPrediction {
+	if len(result.Country) == 0 {
+		e.logger.Warn("No country data found, setting default value", zap.String("name", result.Name))
+		return domain.NationalityPrediction{CountryID: unknownCountry}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status: %s", resp.Status)
+	candidates := make([]domain.NationalityCandidate, 0, len(result.Country))
+	for _, country := range result.Country {
+		candidates = append(candidates, domain.NationalityCandidate{
+			CountryID:   country.CountryID,
+			Probability: country.Probability,
+		})
 	}
-	result := &apiNationalityResponse{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("json decode failed: %w", err)
+	return domain.NationalityPrediction{
+		CountryID:   result.Country[0].CountryID,
+		Probability: result.Country[0].Probability,
+		Candidates:  candidates,
 	}
+}
 
-	if len(result.Country) == 0 {
-		e.logger.Warn("No country data found, setting default value")
-		result.Country = append(result.Country, nationalizeCountry{CountryID: "unknown"})
+// fetchBatch queries endpoint with up to maxBatchSize names per request and
+// maps the responses back to the requested names. The upstream APIs answer a
+// multi-name query with an array in request order.
+func fetchBatch[T any](ctx context.Context, e *Enricher, endpoint string, names []string, countryID string) (map[string]T, error) {
+	unique := uniqueNames(names)
+	results := make(map[string]T, len(unique))
+
+	for start := 0; start < len(unique); start += maxBatchSize {
+		end := min(start+maxBatchSize, len(unique))
+		chunk := unique[start:end]
+
+		var batch []T
+		if err := e.getJSON(ctx, endpoint, withCountry(url.Values{keyNameBatch: chunk}, countryID), &batch); err != nil {
+			return nil, err
+		}
+
+		if len(batch) != len(chunk) {
+			return nil, fmt.Errorf("%w: got %d results for %d names", ErrUnexpectedResponse, len(batch), len(chunk))
+		}
+		for i, name := range chunk {
+			results[name] = batch[i]
+		}
+	}
+
+	return results, nil
+}
+
+// withCountry localizes an agify or genderize query to countryID.
+func withCountry(query url.Values, countryID string) url.Values {
+	if countryID != "" {
+		query.Set(keyCountryID, countryID)
+	}
+	return query
+}
+
+func uniqueNames(names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		unique = append(unique, name)
 	}
-	e.logger.Debug("Response data from api", zap.String("Nationality", result.Country[0].CountryID))
-	return result.Country[0].CountryID, nil
+	return unique
 }
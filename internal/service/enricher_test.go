@@ -0,0 +1,184 @@
+package service
+
+import (
+	"Effective/config"
+	"Effective/pkg/cassette"
+	"Effective/pkg/fakeenrich"
+	"Effective/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func newTestEnricher(t *testing.T, fake *fakeenrich.Server, mode, dir string) *Enricher {
+	t.Helper()
+
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	urls := fakeenrich.URLsFor(srv.URL)
+
+	cfg := &config.Config{
+		APIUrl: &config.APIUrl{
+			AgifyUrl:       urls.Agify,
+			GenderizeUrl:   urls.Genderize,
+			NationalizeUrl: urls.Nationalize,
+		},
+		Enricher: &config.Enricher{
+			Timeout:                 time.Second,
+			MaxRetries:              2,
+			BackoffBase:             time.Millisecond,
+			BackoffMax:              10 * time.Millisecond,
+			BreakerFailureThreshold: 2,
+			BreakerOpenTimeout:      time.Hour,
+			BreakerHalfOpenRequests: 1,
+			HTTPMode:                mode,
+			CassetteDir:             dir,
+		},
+	}
+	return NewEnricher(&logger.Logger{Logger: zap.NewNop()}, cfg)
+}
+
+func TestEnricherFaults(t *testing.T) {
+	tests := []struct {
+		name         string
+		faults       fakeenrich.Faults
+		wantErr      error
+		wantRequests int
+	}{
+		{
+			name:         "no faults",
+			wantRequests: 1,
+		},
+		{
+			name:         "server errors are retried",
+			faults:       fakeenrich.Faults{ErrorRate: 1},
+			wantErr:      ErrUpstreamUnavailable,
+			wantRequests: 3,
+		},
+		{
+			name:         "short rate limits are retried",
+			faults:       fakeenrich.Faults{RateLimitRate: 1},
+			wantErr:      ErrQuotaExhausted,
+			wantRequests: 3,
+		},
+		{
+			name:         "long rate limits are not retried",
+			faults:       fakeenrich.Faults{RateLimitRate: 1, RetryAfter: time.Hour},
+			wantErr:      ErrQuotaExhausted,
+			wantRequests: 1,
+		},
+		{
+			name:         "malformed json is not retried",
+			faults:       fakeenrich.Faults{MalformedRate: 1},
+			wantErr:      ErrUnexpectedResponse,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakeenrich.New(fakeenrich.Config{Faults: tt.faults})
+			e := newTestEnricher(t, fake, cassette.ModePassthrough, "")
+
+			age, err := e.GetAgeByName(context.Background(), "Dmitriy", "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetAgeByName() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && age.Age == 0 {
+				t.Errorf("GetAgeByName() = %+v, want an age", age)
+			}
+			if got := fake.Requests(fakeenrich.APIAgify); got != tt.wantRequests {
+				t.Errorf("agify got %d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestEnricherBreakerOpens(t *testing.T) {
+	fake := fakeenrich.New(fakeenrich.Config{Faults: fakeenrich.Faults{ErrorRate: 1}})
+	e := newTestEnricher(t, fake, cassette.ModePassthrough, "")
+	e.cfg.Enricher.MaxRetries = 0
+
+	for i := 0; i < 2; i++ {
+		if _, err := e.GetGenderByName(context.Background(), "Anna", ""); !errors.Is(err, ErrUpstreamUnavailable) {
+			t.Fatalf("call %d: error = %v, want %v", i, err, ErrUpstreamUnavailable)
+		}
+	}
+	if _, err := e.GetGenderByName(context.Background(), "Anna", ""); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error = %v, want %v", err, ErrCircuitOpen)
+	}
+	if got := fake.Requests(fakeenrich.APIGenderize); got != 2 {
+		t.Errorf("genderize got %d requests, want 2", got)
+	}
+}
+
+func TestEnricherBatchMatchesSingle(t *testing.T) {
+	fake := fakeenrich.New(fakeenrich.Config{})
+	e := newTestEnricher(t, fake, cassette.ModePassthrough, "")
+
+	names := make([]string, 0, 2*maxBatchSize+5)
+	for i := 0; i < cap(names); i++ {
+		names = append(names, fmt.Sprintf("name%d", i))
+	}
+	names = append(names, names[0])
+
+	nationalities, err := e.GetNationalitiesByNames(context.Background(), names)
+	if err != nil {
+		t.Fatalf("GetNationalitiesByNames() error = %v", err)
+	}
+	if got := fake.Requests(fakeenrich.APINationalize); got != 3 {
+		t.Errorf("nationalize got %d requests, want 3", got)
+	}
+	if len(nationalities) != len(names)-1 {
+		t.Fatalf("got %d nationalities, want %d", len(nationalities), len(names)-1)
+	}
+
+	for _, name := range names[:3] {
+		single, err := e.GetNationalityByName(context.Background(), name)
+		if err != nil {
+			t.Fatalf("GetNationalityByName(%q) error = %v", name, err)
+		}
+		if got := nationalities[name]; got.CountryID != single.CountryID || got.Probability != single.Probability {
+			t.Errorf("batch %q = %+v, single = %+v", name, got, single)
+		}
+	}
+}
+
+func TestEnricherReplaysCassette(t *testing.T) {
+	dir := t.TempDir()
+	fake := fakeenrich.New(fakeenrich.Config{})
+
+	recorder := newTestEnricher(t, fake, cassette.ModeRecord, dir)
+	recorded, err := recorder.GetAgeByName(context.Background(), "Olga", "RU")
+	if err != nil {
+		t.Fatalf("recording: %v", err)
+	}
+
+	// A failing upstream proves the answer comes from the cassette.
+	fake.SetConfig(fakeenrich.Config{Faults: fakeenrich.Faults{ErrorRate: 1}})
+	cfg := *recorder.cfg
+	enricherCfg := *cfg.Enricher
+	enricherCfg.HTTPMode = cassette.ModeReplay
+	cfg.Enricher = &enricherCfg
+	replayer := NewEnricher(recorder.logger, &cfg)
+
+	replayed, err := replayer.GetAgeByName(context.Background(), "Olga", "RU")
+	if err != nil {
+		t.Fatalf("replaying: %v", err)
+	}
+	if replayed != recorded {
+		t.Errorf("replayed %+v, recorded %+v", replayed, recorded)
+	}
+	if got := fake.Requests(fakeenrich.APIAgify); got != 0 {
+		t.Errorf("agify got %d requests while replaying, want 0", got)
+	}
+
+	if _, err := replayer.GetAgeByName(context.Background(), "Unrecorded", "RU"); !errors.Is(err, cassette.ErrNoRecording) {
+		t.Errorf("unrecorded name: error = %v, want %v", err, cassette.ErrNoRecording)
+	}
+}
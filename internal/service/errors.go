@@ -0,0 +1,64 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrQuotaExhausted      = errors.New("upstream quota exhausted")
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	ErrUnexpectedResponse  = errors.New("unexpected upstream response")
+	ErrCircuitOpen         = errors.New("circuit breaker open")
+	ErrNameNotFound        = errors.New("name not found")
+	ErrEnrichmentFailed    = errors.New("enrichment failed")
+	ErrInvalidFilter       = errors.New("invalid filter")
+	ErrInvalidFullName     = errors.New("invalid full name")
+	ErrRetentionDisabled   = errors.New("trash retention is not configured")
+	ErrValidation          = errors.New("validation failed")
+)
+
+// EnrichmentError reports the fields that could not be enriched and why.
+// It matches ErrEnrichmentFailed.
+type EnrichmentError struct {
+	Fields map[string]error
+}
+
+func (e *EnrichmentError) Error() string {
+	reasons := make([]string, 0, len(e.Fields))
+	for _, field := range failedFields(e.Fields) {
+		reasons = append(reasons, fmt.Sprintf("%s: %v", field, e.Fields[field]))
+	}
+	return "enrichment failed: " + strings.Join(reasons, "; ")
+}
+
+func (e *EnrichmentError) Is(target error) bool {
+	return target == ErrEnrichmentFailed
+}
+
+// FieldError is a rule a field of a person breaks. Code is machine-readable;
+// Message explains it to a human.
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+// ValidationError lists every field of a person that breaks a rule. It
+// matches ErrValidation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	reasons := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		reasons = append(reasons, fmt.Sprintf("%s: %s", field.Field, field.Message))
+	}
+	return "validation failed: " + strings.Join(reasons, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
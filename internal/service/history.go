@@ -0,0 +1,154 @@
+package service
+
+import (
+	"Effective/internal/domain"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// snapshotFields lists the fields compared between two snapshots, in the
+// order their differences are reported.
+var snapshotFields = []struct {
+	name  string
+	value func(*domain.PersonSnapshot) any
+}{
+	{domain.FieldName, func(s *domain.PersonSnapshot) any { return s.Name }},
+	{domain.FieldSurname, func(s *domain.PersonSnapshot) any { return s.Surname }},
+	{domain.FieldPatronymic, func(s *domain.PersonSnapshot) any { return s.Patronymic }},
+	{domain.FieldAge, func(s *domain.PersonSnapshot) any { return s.Age }},
+	{"age_count", func(s *domain.PersonSnapshot) any { return s.AgeCount }},
+	{"age_source", func(s *domain.PersonSnapshot) any { return s.AgeSource }},
+	{domain.FieldGender, func(s *domain.PersonSnapshot) any { return s.Gender }},
+	{"gender_probability", func(s *domain.PersonSnapshot) any { return s.GenderProbability }},
+	{"gender_count", func(s *domain.PersonSnapshot) any { return s.GenderCount }},
+	{"gender_source", func(s *domain.PersonSnapshot) any { return s.GenderSource }},
+	{domain.FieldNationality, func(s *domain.PersonSnapshot) any { return s.Nationality }},
+	{"nationality_probability", func(s *domain.PersonSnapshot) any { return s.NationalityProbability }},
+	{"nationality_source", func(s *domain.PersonSnapshot) any { return s.NationalitySource }},
+	{"enrichment_status", func(s *domain.PersonSnapshot) any { return s.EnrichmentStatus }},
+	{"deleted_at", func(s *domain.PersonSnapshot) any {
+		if s.DeletedAt == nil {
+			return nil
+		}
+		return s.DeletedAt.UTC().Truncate(time.Microsecond)
+	}},
+}
+
+// GetHistory returns the changes of a person, newest first, each with the
+// fields it changed. Persons in the trash have a history too.
+func (s *PersonService) GetHistory(ctx context.Context, id uuid.UUID) ([]domain.PersonChange, error) {
+	if _, err := s.repo.FindByID(ctx, id, true); err != nil {
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+
+	changes, err := s.repo.History(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get person history: %w", err)
+	}
+	for i := range changes {
+		changes[i].Diff = diffSnapshots(changes[i].Before, changes[i].After)
+	}
+	return changes, nil
+}
+
+// RevertPerson sets the names and attributes of a person back to the values
+// it had at version and saves them as a new change, only if the person is
+// still at expected when that is given. Reverted names are locked as manual
+// changes; reverted attributes keep the source they had at version, and a
+// reverted nationality the candidates it had then.
+func (s *PersonService) RevertPerson(ctx context.Context, id uuid.UUID, version int, expected *int) (*domain.Person, error) {
+	person, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+	if expected != nil {
+		person.Version = *expected
+	}
+
+	snapshot, err := s.repo.GetSnapshot(ctx, id, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get person version: %w", err)
+	}
+
+	nationality, nationalitySource := person.Nationality, person.NationalitySource
+	applySnapshot(ctx, person, snapshot)
+	if person.Nationality != nationality || person.NationalitySource != nationalitySource {
+		person.Nationalities, err = s.repo.GetNationalities(ctx, id, version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get person version: %w", err)
+		}
+	}
+	if err := validatePerson(person); err != nil {
+		return nil, err
+	}
+	s.transliterate(person)
+
+	if err := s.repo.UpdatePerson(ctx, person, domain.ActionRevert, auditFrom(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to revert person: %w", err)
+	}
+	return person, nil
+}
+
+// applySnapshot copies the names and attributes of snapshot to person and
+// records the provenance of every field it changes.
+func applySnapshot(ctx context.Context, person *domain.Person, snapshot *domain.PersonSnapshot) {
+	actor := actorFrom(ctx)
+
+	nameFields := []struct {
+		field string
+		dst   *string
+		value string
+	}{
+		{domain.FieldName, &person.Name, snapshot.Name},
+		{domain.FieldSurname, &person.Surname, snapshot.Surname},
+		{domain.FieldPatronymic, &person.Patronymic, snapshot.Patronymic},
+	}
+	for _, n := range nameFields {
+		if *n.dst != n.value {
+			*n.dst = n.value
+			setProvenance(person, n.field, domain.SourceManual, actor, true)
+		}
+	}
+
+	if person.Age != snapshot.Age || person.AgeSource != snapshot.AgeSource {
+		person.Age, person.AgeCount, person.AgeSource = snapshot.Age, snapshot.AgeCount, snapshot.AgeSource
+		setProvenance(person, domain.FieldAge, snapshot.AgeSource, actor, snapshot.AgeSource == domain.SourceManual)
+	}
+	if person.Gender != snapshot.Gender || person.GenderSource != snapshot.GenderSource {
+		person.Gender, person.GenderProbability, person.GenderCount, person.GenderSource =
+			snapshot.Gender, snapshot.GenderProbability, snapshot.GenderCount, snapshot.GenderSource
+		setProvenance(person, domain.FieldGender, snapshot.GenderSource, actor, snapshot.GenderSource == domain.SourceManual)
+	}
+	if person.Nationality != snapshot.Nationality || person.NationalitySource != snapshot.NationalitySource {
+		person.Nationality, person.NationalityProbability, person.NationalitySource =
+			snapshot.Nationality, snapshot.NationalityProbability, snapshot.NationalitySource
+		setProvenance(person, domain.FieldNationality, snapshot.NationalitySource, actor, snapshot.NationalitySource == domain.SourceManual)
+	}
+}
+
+// diffSnapshots returns the fields that differ between before and after. A
+// nil before, as for a create, reports every field that is set in after.
+func diffSnapshots(before, after *domain.PersonSnapshot) []domain.FieldDiff {
+	diff := make([]domain.FieldDiff, 0)
+	if after == nil {
+		return diff
+	}
+	empty := &domain.PersonSnapshot{}
+	for _, field := range snapshotFields {
+		var old any
+		if before != nil {
+			old = field.value(before)
+		}
+		value := field.value(after)
+		if before == nil && value == field.value(empty) {
+			continue
+		}
+		if old != value {
+			diff = append(diff, domain.FieldDiff{Field: field.name, Before: old, After: value})
+		}
+	}
+	return diff
+}
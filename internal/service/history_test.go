@@ -0,0 +1,158 @@
+package service
+
+import (
+	"Effective/config"
+	"Effective/internal/domain"
+	"Effective/pkg/logger"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// historyRepo keeps one person and the snapshots of its versions. Methods the
+// history tests do not use panic through the nil embedded interface.
+type historyRepo struct {
+	PersonRepository
+
+	person     domain.Person
+	snapshots  map[int]*domain.PersonSnapshot
+	candidates map[int][]domain.NationalityCandidate
+}
+
+func (r *historyRepo) GetByID(_ context.Context, _ uuid.UUID) (*domain.Person, error) {
+	person := r.person
+	return &person, nil
+}
+
+func (r *historyRepo) GetSnapshot(_ context.Context, _ uuid.UUID, version int) (*domain.PersonSnapshot, error) {
+	return r.snapshots[version], nil
+}
+
+func (r *historyRepo) GetNationalities(_ context.Context, _ uuid.UUID, version int) ([]domain.NationalityCandidate, error) {
+	return r.candidates[version], nil
+}
+
+func (r *historyRepo) UpdatePerson(_ context.Context, person *domain.Person, _ string, _ domain.Audit) error {
+	person.Version++
+	r.person = *person
+	r.snapshots[person.Version] = domain.NewPersonSnapshot(person)
+	return nil
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	deletedAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	before := &domain.PersonSnapshot{Name: "Anna", Surname: "Petrova", Age: 30, AgeSource: "agify"}
+	after := &domain.PersonSnapshot{Name: "Anna", Surname: "Petrova", Age: 31, AgeSource: domain.SourceManual, DeletedAt: &deletedAt}
+
+	tests := []struct {
+		name   string
+		before *domain.PersonSnapshot
+		after  *domain.PersonSnapshot
+		want   []domain.FieldDiff
+	}{
+		{
+			name:   "changed fields in order",
+			before: before,
+			after:  after,
+			want: []domain.FieldDiff{
+				{Field: domain.FieldAge, Before: 30, After: 31},
+				{Field: "age_source", Before: "agify", After: domain.SourceManual},
+				{Field: "deleted_at", Before: nil, After: deletedAt.UTC()},
+			},
+		},
+		{
+			name:   "create lists set fields only",
+			before: nil,
+			after:  before,
+			want: []domain.FieldDiff{
+				{Field: domain.FieldName, After: "Anna"},
+				{Field: domain.FieldSurname, After: "Petrova"},
+				{Field: domain.FieldAge, After: 30},
+				{Field: "age_source", After: "agify"},
+			},
+		},
+		{
+			name:   "no change",
+			before: before,
+			after:  before,
+			want:   []domain.FieldDiff{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffSnapshots(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffSnapshots() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevertPersonRoundTrip(t *testing.T) {
+	enriched := domain.Person{
+		ID:                     uuid.New(),
+		Name:                   "Anna",
+		Surname:                "Petrova",
+		Age:                    30,
+		AgeCount:               100,
+		AgeSource:              "agify",
+		Gender:                 "female",
+		GenderProbability:      0.98,
+		GenderSource:           "genderize",
+		Nationality:            "RU",
+		NationalityProbability: 0.7,
+		NationalitySource:      "nationalize",
+		EnrichmentStatus:       domain.EnrichmentCompleted,
+		Version:                1,
+	}
+	edited := enriched
+	edited.Name = "Anne"
+	edited.Age, edited.AgeCount, edited.AgeSource = 31, 0, domain.SourceManual
+	edited.Nationality, edited.NationalityProbability, edited.NationalitySource = "FR", 0, domain.SourceManual
+	edited.Version = 2
+
+	candidates := []domain.NationalityCandidate{{CountryID: "RU", Probability: 0.7}, {CountryID: "UA", Probability: 0.2}}
+	repo := &historyRepo{
+		person: edited,
+		snapshots: map[int]*domain.PersonSnapshot{
+			1: domain.NewPersonSnapshot(&enriched),
+			2: domain.NewPersonSnapshot(&edited),
+		},
+		candidates: map[int][]domain.NationalityCandidate{1: candidates},
+	}
+	cfg := &config.Config{Enricher: &config.Enricher{}}
+	s := NewPersonService(repo, nil, &logger.Logger{Logger: zap.NewNop()}, nil, cfg)
+
+	reverted, err := s.RevertPerson(context.Background(), enriched.ID, 1, nil)
+	if err != nil {
+		t.Fatalf("RevertPerson() error = %v", err)
+	}
+
+	if reverted.Version != 3 {
+		t.Errorf("version = %d, want 3", reverted.Version)
+	}
+	if got, want := repo.snapshots[3], repo.snapshots[1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("reverted snapshot = %+v, want %+v", got, want)
+	}
+	if diff := diffSnapshots(repo.snapshots[1], repo.snapshots[3]); len(diff) != 0 {
+		t.Errorf("diff to version 1 = %+v, want none", diff)
+	}
+	if !reflect.DeepEqual(reverted.Nationalities, candidates) {
+		t.Errorf("nationalities = %+v, want %+v", reverted.Nationalities, candidates)
+	}
+
+	// Names are locked as manual changes, attributes keep their old source.
+	if p := reverted.Provenance[domain.FieldName]; p.Source != domain.SourceManual || !p.Locked {
+		t.Errorf("name provenance = %+v, want locked manual", p)
+	}
+	if p := reverted.Provenance[domain.FieldAge]; p.Source != "agify" || p.Locked {
+		t.Errorf("age provenance = %+v, want unlocked agify", p)
+	}
+	if _, ok := reverted.Provenance[domain.FieldGender]; ok {
+		t.Error("gender provenance set, but gender did not change")
+	}
+}
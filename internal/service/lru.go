@@ -0,0 +1,106 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const defaultLRUSize = 1000
+
+type lruEntry struct {
+	key       string
+	value     any
+	expiresAt time.Time
+}
+
+type lruCache struct {
+	mu    sync.Mutex
+	size  int
+	items map[string]*list.Element
+	order *list.List
+}
+
+func newLRUCache(size int) *lruCache {
+	if size <= 0 {
+		size = defaultLRUSize
+	}
+	return &lruCache{
+		size:  size,
+		items: make(map[string]*list.Element, size),
+		order: list.New(),
+	}
+}
+
+func (c *lruCache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.items, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (c *lruCache) Set(key string, value any, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lruCache) DeleteFunc(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, elem := range c.items {
+		if match(key) {
+			c.order.Remove(elem)
+			delete(c.items, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (c *lruCache) Purge() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := c.order.Len()
+	c.items = make(map[string]*list.Element, c.size)
+	c.order.Init()
+	return removed
+}
+
+func (c *lruCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
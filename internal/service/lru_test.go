@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLRUCache(2)
+	expires := time.Now().Add(time.Hour)
+
+	c.Set("a", 1, expires)
+	c.Set("b", 2, expires)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("Get(a) missed before eviction")
+	}
+	c.Set("c", 3, expires)
+
+	tests := []struct {
+		key    string
+		wantOK bool
+	}{
+		{"a", true},
+		{"b", false},
+		{"c", true},
+	}
+	for _, tt := range tests {
+		if _, ok := c.Get(tt.key); ok != tt.wantOK {
+			t.Errorf("Get(%q) ok = %v, want %v", tt.key, ok, tt.wantOK)
+		}
+	}
+	if got := c.Len(); got != 2 {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+}
+
+func TestLRUCacheExpires(t *testing.T) {
+	c := newLRUCache(2)
+	c.Set("stale", 1, time.Now().Add(-time.Second))
+	c.Set("fresh", 2, time.Now().Add(time.Hour))
+
+	if _, ok := c.Get("stale"); ok {
+		t.Error("Get(stale) hit an expired entry")
+	}
+	if got, ok := c.Get("fresh"); !ok || got != 2 {
+		t.Errorf("Get(fresh) = %v, %v, want 2, true", got, ok)
+	}
+	if got := c.Len(); got != 1 {
+		t.Errorf("Len() = %d, want 1 after dropping the expired entry", got)
+	}
+
+	c.Set("stale", 3, time.Now().Add(time.Hour))
+	if got, ok := c.Get("stale"); !ok || got != 3 {
+		t.Errorf("Get(stale) after Set = %v, %v, want 3, true", got, ok)
+	}
+}
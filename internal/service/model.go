@@ -1,22 +1,41 @@
 package service
 
+import "Effective/internal/domain"
+
 type apiAgeResponse struct {
-	Age int `json:"age"`
+	Name  string `json:"name"`
+	Age   int    `json:"age"`
+	Count int    `json:"count"`
 }
 
 type apiGenderResponse struct {
-	Gender string `json:"gender"`
+	Name        string  `json:"name"`
+	Gender      string  `json:"gender"`
+	Probability float64 `json:"probability"`
+	Count       int     `json:"count"`
 }
 
 type apiNationalityResponse struct {
+	Name    string               `json:"name"`
+	Count   int                  `json:"count"`
 	Country []nationalizeCountry `json:"country"`
 }
 
-type nationalizeCountry struct{
+type nationalizeCountry struct {
 	CountryID   string  `json:"country_id"`
+	Probability float64 `json:"probability"`
+}
+
+func (r apiAgeResponse) prediction() domain.AgePrediction {
+	return domain.AgePrediction{Age: r.Age, Count: r.Count}
+}
+
+func (r apiGenderResponse) prediction() domain.GenderPrediction {
+	return domain.GenderPrediction{Gender: r.Gender, Probability: r.Probability, Count: r.Count}
 }
 
 type EnrichmentData struct {
-    Type  string     
-    Value interface{} 
-}
\ No newline at end of file
+	Type  string
+	Value interface{}
+	Err   error
+}
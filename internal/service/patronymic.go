@@ -0,0 +1,7 @@
+package service
+
+const sourcePatronymic = "patronymic"
+
+// minPatronymicProbability is how likely a patronymic ending has to be for
+// the gender it implies to be used instead of asking a provider.
+const minPatronymicProbability = 0.9
@@ -1,152 +1,765 @@
 package service
 
 import (
+	"Effective/config"
 	"Effective/internal/domain"
+	"Effective/internal/repository"
 	"Effective/internal/transport/http/handler/dto"
 	"Effective/pkg/logger"
+	"Effective/pkg/names"
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
-	"golang.org/x/sync/errgroup"
 )
 
 type PersonService struct {
 	repo     PersonRepository
+	jobs     EnrichmentQueue
 	logger   *logger.Logger
 	enricher EnricherService
+	cfg      *config.Config
 }
 
 type PersonRepository interface {
-	SavePerson(ctx context.Context, person *domain.Person) (uuid.UUID, error)
+	SavePerson(ctx context.Context, person *domain.Person, audit domain.Audit) (uuid.UUID, error)
+	SavePersonWithJob(ctx context.Context, person *domain.Person, job *domain.EnrichmentJob, audit domain.Audit) (uuid.UUID, error)
+	UpdateEnrichment(ctx context.Context, person *domain.Person, audit domain.Audit) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Person, error)
-	DeleteByID(ctx context.Context, id uuid.UUID) (bool, error)
-	UpdatePerson(ctx context.Context, person *domain.Person) error
+	FindByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*domain.Person, error)
+	FindAsOf(ctx context.Context, id uuid.UUID, asOf time.Time, includeDeleted bool) (*domain.Person, error)
+	DeleteByID(ctx context.Context, id uuid.UUID, version *int, audit domain.Audit) (bool, error)
+	RestoreByID(ctx context.Context, id uuid.UUID, audit domain.Audit) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	UpdatePerson(ctx context.Context, person *domain.Person, action string, audit domain.Audit) error
+	History(ctx context.Context, id uuid.UUID) ([]domain.PersonChange, error)
+	GetSnapshot(ctx context.Context, id uuid.UUID, version int) (*domain.PersonSnapshot, error)
+	GetNationalities(ctx context.Context, id uuid.UUID, version int) ([]domain.NationalityCandidate, error)
 	GetPersonFilter(ctx context.Context, person *domain.PersonFilter) (*[]domain.Person, error)
+	ExportPersons(ctx context.Context, filter *domain.PersonFilter, fn func(*domain.Person) error) error
+}
+
+// EnrichmentQueue queues enrichment jobs outside of person writes.
+type EnrichmentQueue interface {
+	EnqueueJob(ctx context.Context, job *domain.EnrichmentJob) (uuid.UUID, error)
+	CreateOperation(ctx context.Context, filter *domain.PersonFilter, maxAttempts int, force bool) (*domain.EnrichmentOperation, error)
+	GetOperation(ctx context.Context, id uuid.UUID) (*domain.EnrichmentOperation, error)
 }
 
 type EnricherService interface {
-	GetAgeByName(ctx context.Context, name string) (int, error)
-	GetGenderByName(ctx context.Context, name string) (string, error)
-	GetNationalityByName(ctx context.Context, name string) (string, error)
+	AgeProvider
+	GenderProvider
+	NationalityProvider
 }
 
-func NewPersonService(repo PersonRepository, logger *logger.Logger, enricher EnricherService) *PersonService {
+func NewPersonService(repo PersonRepository, jobs EnrichmentQueue, logger *logger.Logger, enricher EnricherService, cfg *config.Config) *PersonService {
 	return &PersonService{
 		repo:     repo,
+		jobs:     jobs,
 		logger:   logger,
 		enricher: enricher,
+		cfg:      cfg,
 	}
 }
 
-func (s *PersonService) CreatePerson(ctx context.Context, req *dto.CreatePersonRequest) (uuid.UUID, error) {
-	dataEnrichment := make(chan EnrichmentData, 1)
+// CreatePerson stores a new person. In async mode the person is saved at once
+// with a pending enrichment job; otherwise it is enriched before it is saved
+// and the configured failure policy decides what happens to fields that
+// could not be enriched.
+func (s *PersonService) CreatePerson(ctx context.Context, req *dto.CreatePersonRequest) (*domain.Person, error) {
+	if req.FullName != "" {
+		if _, err := s.SplitFullName(req); err != nil {
+			return nil, err
+		}
+	}
 
-	g, gctx := errgroup.WithContext(ctx)
+	person := &domain.Person{
+		Name:       names.Normalize(req.Name),
+		Surname:    names.Normalize(req.Surname),
+		Patronymic: names.Normalize(req.Patronymic),
+	}
+	if err := validatePerson(person); err != nil {
+		return nil, err
+	}
+	s.transliterate(person)
+	setProvenance(person, domain.FieldName, domain.SourceManual, actorFrom(ctx), true)
+	setProvenance(person, domain.FieldSurname, domain.SourceManual, actorFrom(ctx), true)
+	if person.Patronymic != "" {
+		setProvenance(person, domain.FieldPatronymic, domain.SourceManual, actorFrom(ctx), true)
+	}
 
-	g.Go(func() error {
-		enrichedAge, err := s.enricher.GetAgeByName(gctx, req.Name)
+	if s.cfg.Async.Enabled {
+		person.EnrichmentStatus = domain.EnrichmentPending
+		id, err := s.repo.SavePersonWithJob(ctx, person, s.newJob(req.CountryID, nil), auditFrom(ctx))
 		if err != nil {
-			return fmt.Errorf("failed to enrich age: %w", err)
+			return nil, fmt.Errorf("failed to save person: %w", err)
 		}
-		dataEnrichment <- EnrichmentData{Type: "age", Value: enrichedAge}
-		return nil
-	})
+		person.ID = id
+		return person, nil
+	}
 
-	g.Go(func() error {
-		enrichedGender, err := s.enricher.GetGenderByName(gctx, req.Name)
-		if err != nil {
-			return fmt.Errorf("failed to enrich gender: %w", err)
-		}
-		dataEnrichment <- EnrichmentData{Type: "gender", Value: enrichedGender}
+	failed := s.enrich(ctx, person, req.CountryID, domain.EnrichedFields)
+	if len(failed) > 0 && s.cfg.Enricher.FailurePolicy == config.FailureStrict {
+		return nil, &EnrichmentError{Fields: failed}
+	}
+	markEnriched(person, domain.EnrichedFields, failed)
+
+	var (
+		id  uuid.UUID
+		err error
+	)
+	if len(failed) > 0 && s.cfg.Enricher.FailurePolicy == config.FailureDeferred {
+		id, err = s.repo.SavePersonWithJob(ctx, person, s.newJob(req.CountryID, failedFields(failed)), auditFrom(ctx))
+	} else {
+		id, err = s.repo.SavePerson(ctx, person, auditFrom(ctx))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save person: %w", err)
+	}
+	person.ID = id
+
+	return person, nil
+}
+
+// ParseFullName splits a free-form full name into name, surname and
+// patronymic.
+func (s *PersonService) ParseFullName(fullName string) (names.FullName, error) {
+	name, err := names.Parse(fullName)
+	if err != nil {
+		return names.FullName{}, fmt.Errorf("%w: %w", ErrInvalidFullName, err)
+	}
+	return name, nil
+}
+
+// SplitFullName replaces the full name of req with the names it splits into.
+// The name and surname must be spelled out; a patronymic given as an initial
+// is left out.
+func (s *PersonService) SplitFullName(req *dto.CreatePersonRequest) (names.FullName, error) {
+	name, err := s.ParseFullName(req.FullName)
+	if err != nil {
+		return names.FullName{}, err
+	}
+	if names.IsInitial(name.Name) || names.IsInitial(name.Surname) {
+		return names.FullName{}, fmt.Errorf("%w: name and surname cannot be initials", ErrInvalidFullName)
+	}
+
+	req.Name = name.Name
+	req.Surname = name.Surname
+	if !names.IsInitial(name.Patronymic) {
+		req.Patronymic = name.Patronymic
+	}
+	req.FullName = ""
+	return name, nil
+}
+
+// ProcessEnrichmentJob enriches the fields of job, or every field when the
+// job names none; locked fields are skipped unless the job is forced. While
+// the job has attempts left any provider failure is returned for a retry;
+// the last attempt keeps whatever could be enriched. A job where every
+// provider failed saves nothing, so a re-enrichment never loses existing
+// data. A job whose person was deleted meanwhile is skipped.
+func (s *PersonService) ProcessEnrichmentJob(ctx context.Context, job *domain.EnrichmentJob) error {
+	person, err := s.repo.GetByID(ctx, job.PersonID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		s.logger.Info("Skipping enrichment job of deleted person", zap.String("person_id", job.PersonID.String()))
 		return nil
-	})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get person: %w", err)
+	}
 
-	g.Go(func() error {
-		enrichedNationality, err := s.enricher.GetNationalityByName(gctx, req.Name)
-		if err != nil {
-			return fmt.Errorf("failed to enrich nationality: %w", err)
+	fields := job.Fields
+	if len(fields) == 0 {
+		fields = domain.EnrichedFields
+	}
+	fields = enrichableFields(person, fields, job.Force)
+
+	failed := s.enrich(ctx, person, job.CountryID, fields)
+	if len(failed) == len(fields) || (len(failed) > 0 && job.Attempts < job.MaxAttempts) {
+		return joinEnrichmentErrors(failed)
+	}
+
+	markEnriched(person, fields, failed)
+	if err := s.repo.UpdateEnrichment(ctx, person, domain.Audit{Actor: domain.ActorSystem}); err != nil {
+		return fmt.Errorf("failed to save enrichment: %w", err)
+	}
+
+	return joinEnrichmentErrors(failed)
+}
+
+// EnrichPerson re-runs enrichment for one stored person and saves the fields
+// that could be enriched, following the configured failure policy. Fields
+// locked by a manual change are skipped unless force is set. It fails with an
+// EnrichmentError when every provider failed, or when any failed under the
+// strict policy; existing data is then left untouched.
+func (s *PersonService) EnrichPerson(ctx context.Context, id uuid.UUID, force bool) (*domain.Person, error) {
+	person, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+
+	fields := enrichableFields(person, domain.EnrichedFields, force)
+	if len(fields) == 0 {
+		return person, nil
+	}
+
+	policy := s.cfg.Enricher.FailurePolicy
+	failed := s.enrich(ctx, person, "", fields)
+	if len(failed) > 0 && policy == config.FailureDeferred {
+		job := s.newJob("", failedFields(failed))
+		job.PersonID = person.ID
+		job.Force = force
+		if _, err := s.jobs.EnqueueJob(ctx, job); err != nil {
+			return nil, fmt.Errorf("failed to queue enrichment retry: %w", err)
 		}
-		dataEnrichment <- EnrichmentData{Type: "nationality", Value: enrichedNationality}
-		return nil
-	})
+	}
+	if len(failed) == len(fields) || (len(failed) > 0 && policy == config.FailureStrict) {
+		return nil, &EnrichmentError{Fields: failed}
+	}
+
+	markEnriched(person, fields, failed)
+	if err := s.repo.UpdateEnrichment(ctx, person, auditFrom(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to save enrichment: %w", err)
+	}
+
+	return person, nil
+}
+
+// EnrichPersons queues re-enrichment of every person matching filter and
+// returns the operation that tracks its progress. Pagination is ignored and
+// locked fields are skipped unless force is set.
+func (s *PersonService) EnrichPersons(ctx context.Context, filter *dto.Filter, force bool) (*domain.EnrichmentOperation, error) {
+	personFilter, err := newPersonFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	if personFilter.AsOf != nil {
+		return nil, fmt.Errorf("%w: as_of only applies to reads", ErrInvalidFilter)
+	}
+
+	operation, err := s.jobs.CreateOperation(ctx, personFilter, s.cfg.Async.MaxAttempts, force)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create enrichment operation: %w", err)
+	}
+
+	s.logger.Info("Enrichment operation started", zap.String("operation_id", operation.ID.String()), zap.Int("total", operation.Total))
+	return operation, nil
+}
+
+func (s *PersonService) GetEnrichmentOperation(ctx context.Context, id uuid.UUID) (*domain.EnrichmentOperation, error) {
+	operation, err := s.jobs.GetOperation(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enrichment operation: %w", err)
+	}
+	return operation, nil
+}
+
+func (s *PersonService) newJob(countryID string, fields []string) *domain.EnrichmentJob {
+	return &domain.EnrichmentJob{
+		CountryID:   strings.ToUpper(countryID),
+		Fields:      fields,
+		MaxAttempts: s.cfg.Async.MaxAttempts,
+	}
+}
+
+// enrich fills the given predicted fields of person from the Latin spelling
+// of its name. Providers run concurrently and a failing one does not stop
+// the others: an open circuit breaker fails at once and would otherwise
+// abort every sibling request. Failures are returned per field.
+func (s *PersonService) enrich(ctx context.Context, person *domain.Person, hint string, fields []string) map[string]error {
+	name := s.enrichmentName(person)
+
+	var (
+		countryID           string
+		resolvedNationality *domain.NationalityPrediction
+	)
+	if slices.Contains(fields, attributeAge) || slices.Contains(fields, attributeGender) {
+		countryID, resolvedNationality = s.resolveCountry(ctx, name, countryHint(person, hint, fields))
+	}
+
+	dataEnrichment := make(chan EnrichmentData, 1)
+
+	var wg sync.WaitGroup
+
+	if slices.Contains(fields, attributeAge) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			enrichedAge, err := s.enricher.GetAgeByName(ctx, name, countryID)
+			dataEnrichment <- EnrichmentData{Type: attributeAge, Value: enrichedAge, Err: err}
+		}()
+	}
+
+	if slices.Contains(fields, attributeGender) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if gender, probability, ok := names.PatronymicGender(person.Patronymic); ok && probability >= minPatronymicProbability {
+				dataEnrichment <- EnrichmentData{Type: attributeGender, Value: domain.GenderPrediction{
+					Gender:      gender,
+					Probability: probability,
+					Source:      sourcePatronymic,
+				}}
+				return
+			}
+			enrichedGender, err := s.enricher.GetGenderByName(ctx, name, countryID)
+			dataEnrichment <- EnrichmentData{Type: attributeGender, Value: enrichedGender, Err: err}
+		}()
+	}
+
+	if slices.Contains(fields, attributeNationality) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if resolvedNationality != nil {
+				dataEnrichment <- EnrichmentData{Type: attributeNationality, Value: *resolvedNationality}
+				return
+			}
+			enrichedNationality, err := s.enricher.GetNationalityByName(ctx, name)
+			dataEnrichment <- EnrichmentData{Type: attributeNationality, Value: enrichedNationality, Err: err}
+		}()
+	}
 
 	go func() {
-		err := g.Wait()
-		if err != nil {
-			s.logger.Error("Failed to enrich data", zap.Error(err))
-		}
+		wg.Wait()
 		close(dataEnrichment)
 	}()
 
-	person := &domain.Person{
-		Name:    req.Name,
-		Surname: req.Surname,
+	if person.EnrichmentErrors == nil {
+		person.EnrichmentErrors = make(map[string]string)
 	}
 
+	failed := make(map[string]error)
 	for data := range dataEnrichment {
+		if data.Err == nil {
+			data.Err = validatePrediction(data.Value)
+		}
+		if data.Err != nil {
+			s.logger.Error("Failed to enrich data", zap.String("attribute", data.Type), zap.Error(data.Err))
+			failed[data.Type] = data.Err
+			person.EnrichmentErrors[data.Type] = data.Err.Error()
+			continue
+		}
+		delete(person.EnrichmentErrors, data.Type)
+
 		switch data.Type {
-		case "age":
-			if age, ok := data.Value.(int); ok {
-				person.Age = age
+		case attributeAge:
+			if age, ok := data.Value.(domain.AgePrediction); ok {
+				person.Age = age.Age
+				person.AgeCount = age.Count
+				person.AgeSource = age.Source
+				setProvenance(person, attributeAge, age.Source, domain.ActorSystem, false)
 			}
-		case "gender":
-			if gender, ok := data.Value.(string); ok {
-				person.Gender = gender
+		case attributeGender:
+			if gender, ok := data.Value.(domain.GenderPrediction); ok {
+				person.Gender = gender.Gender
+				person.GenderProbability = gender.Probability
+				person.GenderCount = gender.Count
+				person.GenderSource = gender.Source
+				setProvenance(person, attributeGender, gender.Source, domain.ActorSystem, false)
 			}
-		case "nationality":
-			if nationality, ok := data.Value.(string); ok {
-				person.Nationality = nationality
+		case attributeNationality:
+			if nationality, ok := data.Value.(domain.NationalityPrediction); ok {
+				person.Nationality = nationality.CountryID
+				person.NationalityProbability = nationality.Probability
+				person.NationalitySource = nationality.Source
+				person.Nationalities = nationality.Candidates
+				setProvenance(person, attributeNationality, nationality.Source, domain.ActorSystem, false)
 			}
+		}
+	}
+
+	return failed
+}
+
+// enrichmentName is the Latin spelling of the name of person sent to the
+// providers.
+func (s *PersonService) enrichmentName(person *domain.Person) string {
+	if person.NameLatin != "" {
+		return person.NameLatin
+	}
+	return names.Transliterate(person.Name, s.cfg.Enricher.Transliteration)
+}
 
+// countryHint falls back to the stored nationality of person when no hint
+// is given and the nationality is not being enriched again.
+func countryHint(person *domain.Person, hint string, fields []string) string {
+	if hint == "" && !slices.Contains(fields, attributeNationality) && len(person.Nationality) == 2 {
+		return person.Nationality
+	}
+	return hint
+}
+
+// PrefetchEnrichment looks up the names of jobs with one batch request per
+// attribute and country. The answers land in the enrichment cache, so each
+// job then enriches its person without another provider request. It is best
+// effort: a failure is logged and left for the job itself to hit and retry.
+func (s *PersonService) PrefetchEnrichment(ctx context.Context, jobs []domain.EnrichmentJob) {
+	type prefetch struct {
+		name   string
+		hint   string
+		age    bool
+		gender bool
+	}
+
+	pending := make([]prefetch, 0, len(jobs))
+	nationalityNames := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		person, err := s.repo.GetByID(ctx, job.PersonID)
+		if err != nil {
+			continue
+		}
+
+		fields := job.Fields
+		if len(fields) == 0 {
+			fields = domain.EnrichedFields
+		}
+		fields = enrichableFields(person, fields, job.Force)
+
+		_, probability, ok := names.PatronymicGender(person.Patronymic)
+		patronymicGender := ok && probability >= minPatronymicProbability
+		p := prefetch{
+			name:   s.enrichmentName(person),
+			hint:   strings.ToUpper(countryHint(person, job.CountryID, fields)),
+			age:    slices.Contains(fields, attributeAge),
+			gender: slices.Contains(fields, attributeGender) && !patronymicGender,
+		}
+		twoStep := (p.age || p.gender) && p.hint == "" && s.cfg.Enricher.TwoStepCountry
+		if slices.Contains(fields, attributeNationality) || twoStep {
+			nationalityNames = append(nationalityNames, p.name)
 		}
+		pending = append(pending, p)
 	}
 
-	id, err := s.repo.SavePerson(ctx, person)
+	nationalities := make(map[string]domain.NationalityPrediction)
+	if len(nationalityNames) > 0 {
+		found, err := s.enricher.GetNationalitiesByNames(ctx, nationalityNames)
+		if err != nil {
+			s.logger.Warn("Failed to prefetch nationalities", zap.Int("names", len(nationalityNames)), zap.Error(err))
+		}
+		for name, nationality := range found {
+			nationalities[name] = nationality
+		}
+	}
+
+	// Age and gender are localized, so they are batched per country the way
+	// resolveCountry will pick it for each job.
+	ageNames := make(map[string][]string)
+	genderNames := make(map[string][]string)
+	for _, p := range pending {
+		countryID := p.hint
+		if countryID == "" {
+			countryID = s.cfg.Enricher.DefaultCountry
+			if s.cfg.Enricher.TwoStepCountry {
+				nationality, ok := nationalities[p.name]
+				if !ok {
+					continue
+				}
+				if nationality.CountryID != unknownCountry {
+					countryID = nationality.CountryID
+				}
+			}
+		}
+		if p.age {
+			ageNames[countryID] = append(ageNames[countryID], p.name)
+		}
+		if p.gender {
+			genderNames[countryID] = append(genderNames[countryID], p.name)
+		}
+	}
+
+	for countryID, batch := range ageNames {
+		if _, err := s.enricher.GetAgesByNames(ctx, batch, countryID); err != nil {
+			s.logger.Warn("Failed to prefetch ages", zap.String("country_id", countryID), zap.Int("names", len(batch)), zap.Error(err))
+		}
+	}
+	for countryID, batch := range genderNames {
+		if _, err := s.enricher.GetGendersByNames(ctx, batch, countryID); err != nil {
+			s.logger.Warn("Failed to prefetch genders", zap.String("country_id", countryID), zap.Int("names", len(batch)), zap.Error(err))
+		}
+	}
+}
+
+// markEnriched sets the overall enrichment status of person from its
+// per-field errors. EnrichedAt moves only when this round, which tried
+// fields, enriched one of them.
+func markEnriched(person *domain.Person, fields []string, failed map[string]error) {
+	switch len(person.EnrichmentErrors) {
+	case 0:
+		person.EnrichmentStatus = domain.EnrichmentCompleted
+	case len(domain.EnrichedFields):
+		person.EnrichmentStatus = domain.EnrichmentFailed
+	default:
+		person.EnrichmentStatus = domain.EnrichmentPartial
+	}
+
+	if len(failed) < len(fields) {
+		now := time.Now()
+		person.EnrichedAt = &now
+	}
+}
+
+// enrichableFields drops the fields locked by a manual change unless force
+// is set.
+func enrichableFields(person *domain.Person, fields []string, force bool) []string {
+	if force {
+		return fields
+	}
+	return slices.DeleteFunc(slices.Clone(fields), func(field string) bool {
+		return person.Provenance[field].Locked
+	})
+}
+
+// setProvenance records that field of person was just set from source.
+func setProvenance(person *domain.Person, field, source, actor string, locked bool) {
+	if person.Provenance == nil {
+		person.Provenance = make(map[string]domain.FieldProvenance)
+	}
+	person.Provenance[field] = domain.FieldProvenance{
+		Source:    source,
+		Actor:     actor,
+		UpdatedAt: time.Now(),
+		Locked:    locked,
+	}
+}
+
+func failedFields(failed map[string]error) []string {
+	fields := make([]string, 0, len(failed))
+	for _, field := range domain.EnrichedFields {
+		if _, ok := failed[field]; ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func joinEnrichmentErrors(failed map[string]error) error {
+	errs := make([]error, 0, len(failed))
+	for _, field := range failedFields(failed) {
+		errs = append(errs, fmt.Errorf("failed to enrich %s: %w", field, failed[field]))
+	}
+	return errors.Join(errs...)
+}
+
+// resolveCountry picks the country_id sent to agify and genderize: the
+// request hint, the nationality resolved first in two-step mode, or the
+// configured default. A nationality resolved in two-step mode is returned so
+// it is not requested twice.
+func (s *PersonService) resolveCountry(ctx context.Context, name, hint string) (string, *domain.NationalityPrediction) {
+	if hint != "" {
+		return strings.ToUpper(hint), nil
+	}
+
+	if s.cfg.Enricher.TwoStepCountry {
+		nationality, err := s.enricher.GetNationalityByName(ctx, name)
+		if err != nil {
+			s.logger.Warn("Failed to resolve country for localized enrichment", zap.Error(err))
+			return s.cfg.Enricher.DefaultCountry, nil
+		}
+		if nationality.CountryID != unknownCountry {
+			return nationality.CountryID, &nationality
+		}
+		return s.cfg.Enricher.DefaultCountry, &nationality
+	}
+
+	return s.cfg.Enricher.DefaultCountry, nil
+}
+
+// GetPerson returns a person, also from the trash when includeDeleted is
+// set.
+func (s *PersonService) GetPerson(ctx context.Context, id uuid.UUID, includeDeleted bool) (*domain.Person, error) {
+	person, err := s.repo.FindByID(ctx, id, includeDeleted)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to save person: %w", err)
+		return nil, fmt.Errorf("failed to get person: %w", err)
 	}
+	return person, nil
+}
 
-	return id, nil
+// GetPersonAsOf returns a person as it was at asOf, also from the trash when
+// includeDeleted is set.
+func (s *PersonService) GetPersonAsOf(ctx context.Context, id uuid.UUID, asOf time.Time, includeDeleted bool) (*domain.Person, error) {
+	person, err := s.repo.FindAsOf(ctx, id, asOf, includeDeleted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+	return person, nil
+}
+
+// RestorePerson takes a person out of the trash and returns it.
+func (s *PersonService) RestorePerson(ctx context.Context, id uuid.UUID) (*domain.Person, error) {
+	if err := s.repo.RestoreByID(ctx, id, auditFrom(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to restore person: %w", err)
+	}
+	return s.GetPerson(ctx, id, false)
 }
 
-func (s *PersonService) DeletePerson(ctx context.Context, id uuid.UUID) (bool, error) {
-	_, err := s.repo.DeleteByID(ctx, id)
+// GetTrash lists the persons in the trash matching filter, most recently
+// deleted first unless another order is asked for.
+func (s *PersonService) GetTrash(ctx context.Context, filter *dto.Filter) (*[]domain.Person, error) {
+	personFilter, err := newPersonFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	personFilter.OnlyDeleted = true
+	if len(personFilter.Sort) == 0 {
+		personFilter.Sort = []domain.SortField{{Field: domain.SortDeletedAt, Desc: true}}
+	}
+
+	persons, err := s.repo.GetPersonFilter(ctx, personFilter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: %w", err)
+	}
+	return persons, nil
+}
+
+// PurgeDeleted hard-deletes the persons that have been in the trash for
+// longer than retention, or the configured retention when it is zero, and
+// returns how many were removed.
+func (s *PersonService) PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error) {
+	if retention <= 0 {
+		retention = s.cfg.Trash.Retention
+	}
+	if retention <= 0 {
+		return 0, ErrRetentionDisabled
+	}
+
+	purged, err := s.repo.PurgeDeleted(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trash: %w", err)
+	}
+	if purged > 0 {
+		s.logger.Info("Purged deleted persons", zap.Int64("purged", purged), zap.Duration("retention", retention))
+	}
+	return purged, nil
+}
+
+// DeletePerson soft-deletes a person, only if it is still at version when
+// one is given. It returns false for an unknown person.
+func (s *PersonService) DeletePerson(ctx context.Context, id uuid.UUID, version *int) (bool, error) {
+	ok, err := s.repo.DeleteByID(ctx, id, version, auditFrom(ctx))
 	if err != nil {
 		return false, fmt.Errorf("failed to delete person:%w", err)
 	}
 
-	return true, nil
+	return ok, nil
 }
 
-func (s *PersonService) UpdatePerson(ctx context.Context, id uuid.UUID, req *dto.UpdatePersonRequest) error {
+// UpdatePerson applies req to a person and returns the result. The write
+// fails with a version conflict if the person changed since it was read or,
+// when version is given, if it is no longer at that version.
+func (s *PersonService) UpdatePerson(ctx context.Context, id uuid.UUID, req *dto.UpdatePersonRequest, version *int) (*domain.Person, error) {
 	person, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		return fmt.Errorf("failed to get person: %w", err)
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+	if version != nil {
+		person.Version = *version
 	}
 
+	req.Name = names.Normalize(req.Name)
+	req.Surname = names.Normalize(req.Surname)
+	req.Patronymic = names.Normalize(req.Patronymic)
+
+	before := *person
 	if err := req.NewPerson(person); err != nil {
-		return fmt.Errorf("failed to map person:%w", err)
+		return nil, fmt.Errorf("failed to map person:%w", err)
+	}
+	normalizeAttributes(person)
+	if err := validatePerson(person); err != nil {
+		return nil, err
 	}
+	lockManualChanges(ctx, &before, person)
+	s.transliterate(person)
 
-	if err := s.repo.UpdatePerson(ctx, person); err != nil {
-		return fmt.Errorf("failed to update person:%w", err)
+	if err := s.repo.UpdatePerson(ctx, person, domain.ActionUpdate, auditFrom(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to update person:%w", err)
 	}
 
-	return nil
+	return person, nil
+}
+
+// ReplacePerson sets every editable field of a person from doc and returns
+// the result. Like UpdatePerson it fails with a version conflict if the
+// person changed since it was read or is no longer at version when one is
+// given.
+func (s *PersonService) ReplacePerson(ctx context.Context, id uuid.UUID, doc *dto.PersonDocument, version *int) (*domain.Person, error) {
+	person, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+	if version != nil {
+		person.Version = *version
+	}
+
+	doc.Name = names.Normalize(doc.Name)
+	doc.Surname = names.Normalize(doc.Surname)
+	doc.Patronymic = names.Normalize(doc.Patronymic)
+
+	before := *person
+	doc.Apply(person)
+	normalizeAttributes(person)
+	if err := validatePerson(person); err != nil {
+		return nil, err
+	}
+	lockManualChanges(ctx, &before, person)
+	s.transliterate(person)
+
+	if err := s.repo.UpdatePerson(ctx, person, domain.ActionUpdate, auditFrom(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to replace person:%w", err)
+	}
+
+	return person, nil
+}
+
+// transliterate stores the Latin spelling of the names of person in the
+// configured scheme.
+func (s *PersonService) transliterate(person *domain.Person) {
+	scheme := s.cfg.Enricher.Transliteration
+	person.NameLatin = names.Transliterate(person.Name, scheme)
+	person.SurnameLatin = names.Transliterate(person.Surname, scheme)
+	person.PatronymicLatin = names.Transliterate(person.Patronymic, scheme)
+}
+
+// lockManualChanges records every field that differs between before and
+// person as a locked manual value, so enrichment no longer overwrites it.
+func lockManualChanges(ctx context.Context, before, person *domain.Person) {
+	actor := actorFrom(ctx)
+
+	if person.Name != before.Name {
+		setProvenance(person, domain.FieldName, domain.SourceManual, actor, true)
+	}
+	if person.Surname != before.Surname {
+		setProvenance(person, domain.FieldSurname, domain.SourceManual, actor, true)
+	}
+	if person.Patronymic != before.Patronymic {
+		setProvenance(person, domain.FieldPatronymic, domain.SourceManual, actor, true)
+	}
+	if person.Age != before.Age {
+		person.AgeSource = domain.SourceManual
+		setProvenance(person, domain.FieldAge, domain.SourceManual, actor, true)
+	}
+	if person.Gender != before.Gender {
+		person.GenderSource = domain.SourceManual
+		setProvenance(person, domain.FieldGender, domain.SourceManual, actor, true)
+	}
+	if person.Nationality != before.Nationality {
+		// A manual nationality has no candidates to rank it.
+		person.NationalitySource = domain.SourceManual
+		person.Nationalities = nil
+		setProvenance(person, domain.FieldNationality, domain.SourceManual, actor, true)
+	}
 }
 
 func (s *PersonService) GetPersonWithFilter(ctx context.Context, filter *dto.Filter) (*[]domain.Person, error) {
-	personFilter := &domain.PersonFilter{
-		Name:        filter.Name,
-		Surname:     filter.Surname,
-		MinAge:      filter.MinAge,
-		MaxAge:      filter.MaxAge,
-		Gender:      filter.Gender,
-		Nationality: filter.Nationality,
-		Page:        filter.Page,
-		Size:        filter.Size,
+	personFilter, err := newPersonFilter(filter)
+	if err != nil {
+		return nil, err
 	}
 
 	filterPerson, err := s.repo.GetPersonFilter(ctx, personFilter)
@@ -156,3 +769,82 @@ func (s *PersonService) GetPersonWithFilter(ctx context.Context, filter *dto.Fil
 
 	return filterPerson, nil
 }
+
+// ExportPersons calls fn for every person matching filter, ignoring
+// pagination.
+func (s *PersonService) ExportPersons(ctx context.Context, filter *dto.Filter, fn func(*domain.Person) error) error {
+	personFilter, err := newPersonFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.ExportPersons(ctx, personFilter, fn); err != nil {
+		return fmt.Errorf("failed to export persons: %w", err)
+	}
+	return nil
+}
+
+func newPersonFilter(filter *dto.Filter) (*domain.PersonFilter, error) {
+	sort, err := parseSort(filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+	if filter.AsOf != nil && filter.Includes(dto.IncludeProvenance) {
+		return nil, fmt.Errorf("%w: provenance is not kept for as_of", ErrInvalidFilter)
+	}
+
+	return &domain.PersonFilter{
+		Name:                      normalizeName(filter.Name),
+		Surname:                   normalizeName(filter.Surname),
+		Patronymic:                normalizeName(filter.Patronymic),
+		MinAge:                    filter.MinAge,
+		MaxAge:                    filter.MaxAge,
+		Gender:                    filter.Gender,
+		Nationality:               filter.Nationality,
+		MinAgeCount:               filter.MinAgeCount,
+		MinGenderProbability:      filter.MinGenderProbability,
+		MinGenderCount:            filter.MinGenderCount,
+		MinNationalityProbability: filter.MinNationalityProbability,
+		CandidateCountry:          filter.CandidateCountry,
+		MinCandidateProbability:   filter.MinCandidateProbability,
+		EnrichmentStatus:          filter.EnrichmentStatus,
+		StaleSince:                filter.StaleSince,
+		MissingFields:             filter.MissingFields,
+		WithNationalities:         filter.Includes(dto.IncludeNationalities),
+		WithProvenance:            filter.Includes(dto.IncludeProvenance),
+		IncludeDeleted:            filter.IncludeDeleted,
+		AsOf:                      filter.AsOf,
+		Sort:                      sort,
+		Page:                      filter.Page,
+		Size:                      filter.Size,
+	}, nil
+}
+
+// normalizeName normalizes a name filter so it matches stored names however
+// it was typed.
+func normalizeName(name *string) *string {
+	if name == nil {
+		return nil
+	}
+	normalized := names.Normalize(*name)
+	return &normalized
+}
+
+// parseSort reads a comma-separated list of sortable fields, each descending
+// when prefixed with "-".
+func parseSort(sort string) ([]domain.SortField, error) {
+	var fields []domain.SortField
+	for _, name := range strings.Split(sort, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		field := domain.SortField{Field: strings.TrimPrefix(name, "-"), Desc: strings.HasPrefix(name, "-")}
+		if !slices.Contains(domain.SortableFields, field.Field) {
+			return nil, fmt.Errorf("%w: cannot sort by %q", ErrInvalidFilter, field.Field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"Effective/internal/domain"
+	"context"
+	"fmt"
+	"strconv"
+)
+
+type AgeProvider interface {
+	GetAgeByName(ctx context.Context, name, countryID string) (domain.AgePrediction, error)
+	GetAgesByNames(ctx context.Context, names []string, countryID string) (map[string]domain.AgePrediction, error)
+}
+
+type GenderProvider interface {
+	GetGenderByName(ctx context.Context, name, countryID string) (domain.GenderPrediction, error)
+	GetGendersByNames(ctx context.Context, names []string, countryID string) (map[string]domain.GenderPrediction, error)
+}
+
+type NationalityProvider interface {
+	GetNationalityByName(ctx context.Context, name string) (domain.NationalityPrediction, error)
+	GetNationalitiesByNames(ctx context.Context, names []string) (map[string]domain.NationalityPrediction, error)
+}
+
+// staticProvider answers every name with the same configured value. It is
+// meant as the last link of a chain so that a person is never saved empty.
+type staticProvider struct {
+	value       string
+	probability float64
+}
+
+func newStaticProvider(value string, probability float64) *staticProvider {
+	return &staticProvider{value: value, probability: probability}
+}
+
+// check validates the configured value as an answer for attribute.
+func (p *staticProvider) check(attribute string) error {
+	var (
+		value any
+		err   error
+	)
+	switch attribute {
+	case attributeAge:
+		value, err = p.GetAgeByName(context.Background(), "", "")
+	case attributeGender:
+		value, err = p.GetGenderByName(context.Background(), "", "")
+	case attributeNationality:
+		value, err = p.GetNationalityByName(context.Background(), "")
+	}
+	if err != nil {
+		return err
+	}
+	if err := validatePrediction(value); err != nil {
+		return fmt.Errorf("invalid static %s: %w", attribute, err)
+	}
+	return nil
+}
+
+func (p *staticProvider) GetAgeByName(_ context.Context, _, _ string) (domain.AgePrediction, error) {
+	age, err := strconv.Atoi(p.value)
+	if err != nil {
+		return domain.AgePrediction{}, fmt.Errorf("invalid static age %q: %w", p.value, err)
+	}
+	return domain.AgePrediction{Age: age}, nil
+}
+
+func (p *staticProvider) GetGenderByName(_ context.Context, _, _ string) (domain.GenderPrediction, error) {
+	return domain.GenderPrediction{Gender: p.value, Probability: p.probability}, nil
+}
+
+func (p *staticProvider) GetNationalityByName(_ context.Context, _ string) (domain.NationalityPrediction, error) {
+	return domain.NationalityPrediction{
+		CountryID:   p.value,
+		Probability: p.probability,
+		Candidates:  []domain.NationalityCandidate{{CountryID: p.value, Probability: p.probability}},
+	}, nil
+}
+
+func (p *staticProvider) GetAgesByNames(ctx context.Context, names []string, countryID string) (map[string]domain.AgePrediction, error) {
+	return staticBatch(names, func(name string) (domain.AgePrediction, error) {
+		return p.GetAgeByName(ctx, name, countryID)
+	})
+}
+
+func (p *staticProvider) GetGendersByNames(ctx context.Context, names []string, countryID string) (map[string]domain.GenderPrediction, error) {
+	return staticBatch(names, func(name string) (domain.GenderPrediction, error) {
+		return p.GetGenderByName(ctx, name, countryID)
+	})
+}
+
+func (p *staticProvider) GetNationalitiesByNames(ctx context.Context, names []string) (map[string]domain.NationalityPrediction, error) {
+	return staticBatch(names, func(name string) (domain.NationalityPrediction, error) {
+		return p.GetNationalityByName(ctx, name)
+	})
+}
+
+func staticBatch[T any](names []string, get func(name string) (T, error)) (map[string]T, error) {
+	results := make(map[string]T, len(names))
+	for _, name := range names {
+		value, err := get(name)
+		if err != nil {
+			return nil, err
+		}
+		results[name] = value
+	}
+	return results, nil
+}
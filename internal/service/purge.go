@@ -0,0 +1,83 @@
+package service
+
+import (
+	"Effective/config"
+	"Effective/pkg/logger"
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type TrashPurger interface {
+	PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// Purger periodically hard-deletes the persons that have been in the trash
+// for longer than the configured retention.
+type Purger struct {
+	trash  TrashPurger
+	logger *logger.Logger
+	cfg    *config.Trash
+
+	wg sync.WaitGroup
+}
+
+func NewPurger(trash TrashPurger, logger *logger.Logger, cfg *config.Trash) *Purger {
+	return &Purger{
+		trash:  trash,
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+// Start launches the purge loop unless retention is disabled. It stops once
+// ctx is cancelled.
+func (p *Purger) Start(ctx context.Context) {
+	if p.cfg.Retention <= 0 {
+		p.logger.Info("Trash retention disabled, deleted persons are kept")
+		return
+	}
+
+	interval := p.cfg.PurgeInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	p.logger.Info("Starting trash purger", zap.Duration("retention", p.cfg.Retention), zap.Duration("interval", interval))
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := p.trash.PurgeDeleted(ctx, p.cfg.Retention); err != nil && ctx.Err() == nil {
+				p.logger.Error("Failed to purge trash", zap.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the purge loop has stopped or ctx expires.
+func (p *Purger) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"Effective/config"
+	"Effective/pkg/logger"
+	"fmt"
+)
+
+// NewProviderChain builds the per-attribute provider chains described by
+// cfg.Providers. HTTP providers share enricher, so each URL keeps its own
+// retries, quota tracking and circuit breaker.
+func NewProviderChain(cfg *config.Config, enricher *Enricher, logger *logger.Logger) (*ProviderChain, error) {
+	chain := &ProviderChain{
+		logger:   logger,
+		datasets: make(map[string]*DatasetProvider),
+		remote:   make(map[string]bool),
+	}
+
+	for _, p := range cfg.Providers.Age {
+		provider, err := chain.newProvider(attributeAge, p, enricher)
+		if err != nil {
+			return nil, fmt.Errorf("age provider %s: %w", p.Name, err)
+		}
+		chain.age = append(chain.age, newChainLink[AgeProvider](p, provider))
+	}
+
+	for _, p := range cfg.Providers.Gender {
+		provider, err := chain.newProvider(attributeGender, p, enricher)
+		if err != nil {
+			return nil, fmt.Errorf("gender provider %s: %w", p.Name, err)
+		}
+		chain.gender = append(chain.gender, newChainLink[GenderProvider](p, provider))
+	}
+
+	for _, p := range cfg.Providers.Nationality {
+		provider, err := chain.newProvider(attributeNationality, p, enricher)
+		if err != nil {
+			return nil, fmt.Errorf("nationality provider %s: %w", p.Name, err)
+		}
+		chain.nationality = append(chain.nationality, newChainLink[NationalityProvider](p, provider))
+	}
+
+	return chain, nil
+}
+
+// newProvider builds the provider for one chain entry of attribute. Dataset
+// providers are shared by path so one file is loaded and reloaded only once.
+// A static value must pass the rules of attribute, so a bad setting stops
+// the start instead of failing every write that falls back to it.
+func (c *ProviderChain) newProvider(attribute string, p config.Provider, enricher *Enricher) (EnricherService, error) {
+	switch p.Type {
+	case config.ProviderHTTP:
+		if p.URL == "" {
+			return nil, fmt.Errorf("url is required for %s providers", p.Type)
+		}
+		enricher.breaker(p.URL)
+		c.remote[p.Name] = true
+		return enricher.endpoint(p.URL), nil
+	case config.ProviderStatic:
+		if p.Value == "" {
+			return nil, fmt.Errorf("value is required for %s providers", p.Type)
+		}
+		provider := newStaticProvider(p.Value, p.Probability)
+		if err := provider.check(attribute); err != nil {
+			return nil, err
+		}
+		return provider, nil
+	case config.ProviderDataset:
+		if p.Path == "" {
+			return nil, fmt.Errorf("path is required for %s providers", p.Type)
+		}
+		if dataset, ok := c.datasets[p.Path]; ok {
+			return dataset, nil
+		}
+		dataset, err := NewDatasetProvider(p.Path)
+		if err != nil {
+			return nil, err
+		}
+		c.datasets[p.Path] = dataset
+		return dataset, nil
+	default:
+		return nil, fmt.Errorf("unknown provider type %q", p.Type)
+	}
+}
+
+func newChainLink[P any](p config.Provider, provider P) chainLink[P] {
+	return chainLink[P]{
+		name:           p.Name,
+		provider:       provider,
+		minProbability: p.MinProbability,
+		minCount:       p.MinCount,
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"Effective/pkg/cassette"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	headerRetryAfter         = "Retry-After"
+	headerRateLimitRemaining = "X-Rate-Limit-Remaining"
+	headerRateLimitReset     = "X-Rate-Limit-Reset"
+)
+
+// rateLimit remembers the quota an upstream reported in its last response, so
+// that an exhausted quota fails fast until it resets.
+type rateLimit struct {
+	remaining int
+	resetAt   time.Time
+}
+
+// getJSON sends an idempotent GET to endpoint through its circuit breaker
+// and decodes the body into out. Only ErrUpstreamUnavailable counts as a
+// breaker failure; quota and response errors leave the breaker untouched.
+func (e *Enricher) getJSON(ctx context.Context, endpoint string, query url.Values, out any) error {
+	b := e.breaker(endpoint)
+	if !b.Allow() {
+		return fmt.Errorf("%w: %s", ErrCircuitOpen, endpoint)
+	}
+
+	err := e.getJSONWithRetry(ctx, endpoint, query, out)
+	switch {
+	case err == nil:
+		b.Success()
+	case errors.Is(err, ErrUpstreamUnavailable):
+		b.Failure(err)
+	case errors.Is(err, ErrUnexpectedResponse):
+		b.Success()
+	default:
+		b.Release()
+	}
+	return err
+}
+
+// getJSONWithRetry retries timeouts, 5xx and short 429s with exponential
+// backoff and full jitter; a 429 whose wait exceeds the backoff ceiling is
+// reported as ErrQuotaExhausted.
+func (e *Enricher) getJSONWithRetry(ctx context.Context, endpoint string, query url.Values, out any) error {
+	if err := e.checkQuota(endpoint); err != nil {
+		return err
+	}
+
+	cfg := e.cfg.Enricher
+	var lastErr error
+
+	for attempt := 0; attempt <= cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			wait := backoff(attempt, cfg.BackoffBase, cfg.BackoffMax)
+			var upstreamErr *retryAfterError
+			if errors.As(lastErr, &upstreamErr) && upstreamErr.wait > 0 {
+				wait = min(upstreamErr.wait, cfg.BackoffMax)
+			}
+
+			e.logger.Warn("Retrying upstream request",
+				zap.String("url", endpoint),
+				zap.Int("attempt", attempt),
+				zap.Duration("wait", wait),
+				zap.Error(lastErr),
+			)
+			if err := sleep(ctx, wait); err != nil {
+				return err
+			}
+		}
+
+		retry, err := e.doGet(ctx, endpoint, query, out)
+		if err == nil {
+			return nil
+		}
+		if !retry {
+			return err
+		}
+		lastErr = err
+	}
+
+	if errors.Is(lastErr, ErrQuotaExhausted) {
+		return lastErr
+	}
+	return fmt.Errorf("%w: %s after %d attempts: %w", ErrUpstreamUnavailable, endpoint, cfg.MaxRetries+1, lastErr)
+}
+
+// doGet performs a single attempt and reports whether a failure is worth
+// retrying.
+func (e *Enricher) doGet(ctx context.Context, endpoint string, query url.Values, out any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, fmt.Errorf("request failed: %w", err)
+	}
+	req.URL.RawQuery = query.Encode()
+	e.logger.Debug("Request URL", zap.String("url", req.URL.String()))
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		if errors.Is(err, cassette.ErrNoRecording) {
+			return false, err
+		}
+		return true, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	e.updateQuota(endpoint, resp.Header)
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		wait := retryAfter(resp.Header)
+		if wait > e.cfg.Enricher.BackoffMax {
+			return false, fmt.Errorf("%w: %s, retry after %s", ErrQuotaExhausted, endpoint, wait)
+		}
+		return true, &retryAfterError{
+			wait: wait,
+			err:  fmt.Errorf("%w: %s", ErrQuotaExhausted, resp.Status),
+		}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return true, &retryAfterError{
+			wait: retryAfter(resp.Header),
+			err:  fmt.Errorf("unexpected status: %s", resp.Status),
+		}
+	case resp.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("%w: unexpected status: %s", ErrUnexpectedResponse, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("%w: json decode failed: %w", ErrUnexpectedResponse, err)
+	}
+	return false, nil
+}
+
+func (e *Enricher) checkQuota(endpoint string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	limit, ok := e.limits[endpoint]
+	if !ok || limit.remaining > 0 {
+		return nil
+	}
+	if time.Now().After(limit.resetAt) {
+		delete(e.limits, endpoint)
+		return nil
+	}
+	return fmt.Errorf("%w: %s, resets at %s", ErrQuotaExhausted, endpoint, limit.resetAt.Format(time.RFC3339))
+}
+
+func (e *Enricher) updateQuota(endpoint string, header http.Header) {
+	remaining, err := strconv.Atoi(header.Get(headerRateLimitRemaining))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.Atoi(header.Get(headerRateLimitReset))
+	if err != nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.limits[endpoint] = &rateLimit{
+		remaining: remaining,
+		resetAt:   time.Now().Add(time.Duration(reset) * time.Second),
+	}
+	if remaining == 0 {
+		e.logger.Warn("Upstream quota exhausted", zap.String("url", endpoint), zap.Int("reset_seconds", reset))
+	}
+}
+
+type retryAfterError struct {
+	wait time.Duration
+	err  error
+}
+
+func (e *retryAfterError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryAfterError) Unwrap() error {
+	return e.err
+}
+
+// retryAfter reads the wait requested by Retry-After, falling back to
+// X-Rate-Limit-Reset, and returns zero when neither is present.
+func retryAfter(header http.Header) time.Duration {
+	if value := header.Get(headerRetryAfter); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return max(time.Until(at), 0)
+		}
+	}
+	if seconds, err := strconv.Atoi(header.Get(headerRateLimitReset)); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
+
+func backoff(attempt int, base, ceiling time.Duration) time.Duration {
+	wait := base << (attempt - 1)
+	if wait <= 0 || wait > ceiling {
+		wait = ceiling
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return rand.N(wait) + 1
+}
+
+func sleep(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"Effective/internal/domain"
+	"Effective/pkg/countries"
+	"Effective/pkg/names"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Codes of the rules a field can break.
+const (
+	CodeRequired       = "required"
+	CodeTooShort       = "too_short"
+	CodeTooLong        = "too_long"
+	CodeInvalidName    = "invalid_name"
+	CodeOutOfRange     = "out_of_range"
+	CodeUnknownGender  = "unknown_gender"
+	CodeUnknownCountry = "unknown_country"
+)
+
+// Bounds of the person fields, matched by the CHECK constraints on persons.
+const (
+	minNameLength = 2
+	maxNameLength = 50
+	maxAge        = 150
+)
+
+// genders are the genders a person can have; empty means unknown.
+var genders = []string{"", "male", "female"}
+
+// normalizeAttributes brings the gender to lower case and the nationality to
+// upper case, as the providers return them.
+func normalizeAttributes(person *domain.Person) {
+	person.Gender = strings.ToLower(strings.TrimSpace(person.Gender))
+	person.Nationality = strings.ToUpper(strings.TrimSpace(person.Nationality))
+}
+
+// validatePerson checks the names and attributes of person and returns a
+// ValidationError listing every field that breaks a rule.
+func validatePerson(person *domain.Person) error {
+	var fields []FieldError
+	add := func(field, code, format string, args ...any) {
+		fields = append(fields, FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, name := range []struct {
+		field    string
+		value    string
+		required bool
+	}{
+		{domain.FieldName, person.Name, true},
+		{domain.FieldSurname, person.Surname, true},
+		{domain.FieldPatronymic, person.Patronymic, false},
+	} {
+		length := utf8.RuneCountInString(name.value)
+		switch {
+		case name.value == "":
+			if name.required {
+				add(name.field, CodeRequired, "%s is required", name.field)
+			}
+		case length < minNameLength:
+			add(name.field, CodeTooShort, "%s must be at least %d characters", name.field, minNameLength)
+		case length > maxNameLength:
+			add(name.field, CodeTooLong, "%s must be at most %d characters", name.field, maxNameLength)
+		case !names.Valid(name.value):
+			add(name.field, CodeInvalidName, "%s must be letters joined by spaces, hyphens or apostrophes", name.field)
+		}
+	}
+
+	if person.Age < 0 || person.Age > maxAge {
+		add(domain.FieldAge, CodeOutOfRange, "age must be between 0 and %d", maxAge)
+	}
+	if !slices.Contains(genders, person.Gender) {
+		add(domain.FieldGender, CodeUnknownGender, "gender must be male or female")
+	}
+	if person.Nationality != "" && person.Nationality != unknownCountry && !countries.Valid(person.Nationality) {
+		add(domain.FieldNationality, CodeUnknownCountry, "nationality must be an ISO 3166-1 alpha-2 country code")
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// validatePrediction checks a provider answer against the rules of the field
+// it fills, so a bad answer fails that field instead of the whole write.
+func validatePrediction(value any) error {
+	switch v := value.(type) {
+	case domain.AgePrediction:
+		if v.Age < 0 || v.Age > maxAge || v.Count < 0 {
+			return fmt.Errorf("age %d out of range", v.Age)
+		}
+	case domain.GenderPrediction:
+		if !slices.Contains(genders, v.Gender) || v.Count < 0 {
+			return fmt.Errorf("unknown gender %q", v.Gender)
+		}
+		if v.Probability < 0 || v.Probability > 1 {
+			return fmt.Errorf("gender probability %v out of range", v.Probability)
+		}
+	case domain.NationalityPrediction:
+		if v.CountryID != "" && v.CountryID != unknownCountry && !countries.Valid(v.CountryID) {
+			return fmt.Errorf("unknown country %q", v.CountryID)
+		}
+		if v.Probability < 0 || v.Probability > 1 {
+			return fmt.Errorf("nationality probability %v out of range", v.Probability)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"Effective/config"
+	"Effective/internal/domain"
+	"Effective/pkg/logger"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type EnrichmentJobRepository interface {
+	ClaimJobs(ctx context.Context, limit int, lease time.Duration) ([]domain.EnrichmentJob, error)
+	CompleteJob(ctx context.Context, id uuid.UUID) error
+	FailJob(ctx context.Context, job *domain.EnrichmentJob, reason string, retryAt time.Time) error
+}
+
+type EnrichmentJobProcessor interface {
+	PrefetchEnrichment(ctx context.Context, jobs []domain.EnrichmentJob)
+	ProcessEnrichmentJob(ctx context.Context, job *domain.EnrichmentJob) error
+}
+
+// EnrichmentWorker runs a pool of goroutines that claim enrichment jobs from
+// the queue and process them. Failed jobs are retried with exponential
+// backoff until they run out of attempts.
+type EnrichmentWorker struct {
+	jobs      EnrichmentJobRepository
+	processor EnrichmentJobProcessor
+	logger    *logger.Logger
+	cfg       *config.AsyncEnrichment
+
+	wg sync.WaitGroup
+}
+
+func NewEnrichmentWorker(jobs EnrichmentJobRepository, processor EnrichmentJobProcessor, logger *logger.Logger, cfg *config.AsyncEnrichment) *EnrichmentWorker {
+	return &EnrichmentWorker{
+		jobs:      jobs,
+		processor: processor,
+		logger:    logger,
+		cfg:       cfg,
+	}
+}
+
+// Start launches the workers. They stop claiming new jobs once ctx is
+// cancelled; a job already claimed runs to completion.
+func (w *EnrichmentWorker) Start(ctx context.Context) {
+	workers := max(w.cfg.Workers, 1)
+	w.logger.Info("Starting enrichment workers", zap.Int("workers", workers))
+
+	for i := 0; i < workers; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.poll(ctx)
+		}()
+	}
+}
+
+// Wait blocks until every worker has finished its in-flight job or ctx
+// expires.
+func (w *EnrichmentWorker) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.logger.Info("Enrichment workers stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *EnrichmentWorker) poll(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain the queue before waiting for the next tick.
+		for ctx.Err() == nil && w.claimAndProcess(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// claimAndProcess runs a batch of jobs and reports whether any was claimed.
+// The names of the batch are prefetched first, so a bulk re-enrichment makes
+// one provider request per attribute and batch instead of one per person.
+func (w *EnrichmentWorker) claimAndProcess(ctx context.Context) bool {
+	jobs, err := w.jobs.ClaimJobs(ctx, max(w.cfg.BatchSize, 1), w.cfg.JobLease)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Error("Failed to claim enrichment jobs", zap.Error(err))
+		}
+		return false
+	}
+	if len(jobs) == 0 {
+		return false
+	}
+
+	if len(jobs) > 1 {
+		prefetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), w.cfg.JobTimeout)
+		w.processor.PrefetchEnrichment(prefetchCtx, jobs)
+		cancel()
+	}
+
+	for i := range jobs {
+		w.process(context.WithoutCancel(ctx), &jobs[i])
+	}
+	return true
+}
+
+func (w *EnrichmentWorker) process(ctx context.Context, job *domain.EnrichmentJob) {
+	jobCtx, cancel := context.WithTimeout(ctx, w.cfg.JobTimeout)
+	defer cancel()
+
+	log := w.logger.With(zap.String("job_id", job.ID.String()), zap.String("person_id", job.PersonID.String()), zap.Int("attempt", job.Attempts))
+
+	if err := w.processor.ProcessEnrichmentJob(jobCtx, job); err != nil {
+		retryAt := time.Now().Add(w.retryDelay(job.Attempts))
+		log.Warn("Enrichment job failed", zap.Error(err), zap.Time("retry_at", retryAt))
+		if err := w.jobs.FailJob(ctx, job, err.Error(), retryAt); err != nil {
+			log.Error("Failed to record enrichment job failure", zap.Error(err))
+		}
+		return
+	}
+
+	if err := w.jobs.CompleteJob(ctx, job.ID); err != nil {
+		log.Error("Failed to complete enrichment job", zap.Error(err))
+		return
+	}
+	log.Debug("Enrichment job completed")
+}
+
+// retryDelay doubles the configured delay for every attempt already made.
+func (w *EnrichmentWorker) retryDelay(attempts int) time.Duration {
+	delay := w.cfg.RetryDelay
+	for i := 1; i < attempts && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	return delay
+}
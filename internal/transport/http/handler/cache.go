@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"Effective/internal/service"
+	"Effective/pkg/logger"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type CacheHandler struct {
+	cache  *service.CachedEnricher
+	logger *logger.Logger
+}
+
+type InvalidateCacheResponse struct {
+	Removed int64 `json:"removed"`
+}
+
+func NewCacheHandler(
+	cache *service.CachedEnricher,
+	logger *logger.Logger,
+) *CacheHandler {
+	return &CacheHandler{
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// GetCacheStats godoc
+// @Summary Get enrichment cache statistics
+// @Description Hit and miss counters of the enrichment cache
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} service.CacheStats
+// @Failure 403 {object} handler.ErrResponse
+// @Security AdminToken
+// @Router /admin/enrichment-cache [get]
+func (h *CacheHandler) GetCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.cache.Stats())
+}
+
+// InvalidateName godoc
+// @Summary Invalidate cached enrichment for a name
+// @Description Remove every cached enrichment value for the given name
+// @Tags Admin
+// @Produce json
+// @Param name path string true "Name"
+// @Success 200 {object} handler.InvalidateCacheResponse
+// @Failure 403 {object} handler.ErrResponse
+// @Failure 500 {object} handler.ErrResponse
+// @Security AdminToken
+// @Router /admin/enrichment-cache/{name} [delete]
+func (h *CacheHandler) InvalidateName(c *gin.Context) {
+	name := c.Param("name")
+
+	removed, err := h.cache.Invalidate(c.Request.Context(), name)
+	if err != nil {
+		h.logger.Error("failed to invalidate cache", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, InvalidateCacheResponse{Removed: removed})
+}
+
+// InvalidateAll godoc
+// @Summary Invalidate the whole enrichment cache
+// @Description Remove every cached enrichment value
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} handler.InvalidateCacheResponse
+// @Failure 403 {object} handler.ErrResponse
+// @Failure 500 {object} handler.ErrResponse
+// @Security AdminToken
+// @Router /admin/enrichment-cache [delete]
+func (h *CacheHandler) InvalidateAll(c *gin.Context) {
+	removed, err := h.cache.InvalidateAll(c.Request.Context())
+	if err != nil {
+		h.logger.Error("failed to invalidate cache", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, InvalidateCacheResponse{Removed: removed})
+}
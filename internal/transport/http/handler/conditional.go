@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"Effective/internal/repository"
+	"Effective/internal/transport/http/handler/dto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	headerETag            = "ETag"
+	headerLastModified    = "Last-Modified"
+	headerIfMatch         = "If-Match"
+	headerIfNoneMatch     = "If-None-Match"
+	headerIfModifiedSince = "If-Modified-Since"
+)
+
+// personETag returns the strong ETag of a person at version. Every write
+// moves the version, so it changes with the representation.
+func personETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion reads the person version required by the If-Match header:
+// nil without the header or for "*". It returns false for a header that
+// cannot match any version.
+func ifMatchVersion(c *gin.Context) (*int, bool) {
+	header := strings.TrimSpace(c.GetHeader(headerIfMatch))
+	if header == "" || header == "*" {
+		return nil, true
+	}
+
+	version, err := strconv.Atoi(strings.Trim(header, `"`))
+	if err != nil || header != personETag(version) {
+		return nil, false
+	}
+	return &version, true
+}
+
+// writeConditional writes body as JSON with etag and a Last-Modified of
+// lastModified, or 304 when the request's If-None-Match or, without one,
+// If-Modified-Since shows the client's copy is current.
+func writeConditional(c *gin.Context, etag string, lastModified time.Time, body any) error {
+	c.Header(headerETag, etag)
+	if !lastModified.IsZero() {
+		c.Header(headerLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return nil
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+	return nil
+}
+
+func notModified(req *http.Request, etag string, lastModified time.Time) bool {
+	if inm := req.Header.Get(headerIfNoneMatch); inm != "" {
+		return etagMatches(inm, etag)
+	}
+
+	ims, err := http.ParseTime(req.Header.Get(headerIfModifiedSince))
+	if err != nil || lastModified.IsZero() {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(ims)
+}
+
+// etagMatches reports whether the comma-separated list of entity tags in
+// header matches etag by weak comparison, or is "*".
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// preconditionFailed answers a failed If-Match with 412 and the current
+// person, or 404 when it no longer exists.
+func (h *PersonHandler) preconditionFailed(c *gin.Context, id uuid.UUID) {
+	person, err := h.service.GetPerson(c.Request.Context(), id, false)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, ErrResponse{Error: "Person not found"})
+			return
+		}
+		h.logger.Error("failed to get person", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		return
+	}
+
+	c.Header(headerETag, personETag(person.Version))
+	c.JSON(http.StatusPreconditionFailed, dto.NewPersonResponse(person))
+}
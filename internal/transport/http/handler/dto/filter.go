@@ -1,12 +1,71 @@
 package dto
 
+import (
+	"strings"
+	"time"
+)
+
+const (
+	IncludeNationalities = "nationalities"
+	IncludeProvenance    = "provenance"
+)
+
 type Filter struct {
 	Name        *string `form:"name"`
 	Surname     *string `form:"surname"`
+	Patronymic  *string `form:"patronymic"`
 	MinAge      *int    `form:"min_age"`
 	MaxAge      *int    `form:"max_age"`
 	Gender      *string `form:"gender"`
 	Nationality *string `form:"nationality"`
-	Page        int     `form:"page"`
-	Size        int     `form:"size"`
+
+	MinAgeCount               *int     `form:"min_age_count" binding:"omitempty,min=0"`
+	MinGenderProbability      *float64 `form:"min_gender_probability" binding:"omitempty,min=0,max=1"`
+	MinGenderCount            *int     `form:"min_gender_count" binding:"omitempty,min=0"`
+	MinNationalityProbability *float64 `form:"min_nationality_probability" binding:"omitempty,min=0,max=1"`
+
+	CandidateCountry        *string  `form:"candidate_country" binding:"omitempty,len=2"`
+	MinCandidateProbability *float64 `form:"min_candidate_probability" binding:"omitempty,min=0,max=1"`
+
+	EnrichmentStatus *string    `form:"enrichment_status" binding:"omitempty,oneof=pending completed partial failed"`
+	StaleSince       *time.Time `form:"stale_since" time_format:"2006-01-02T15:04:05Z07:00"`
+	MissingFields    []string   `form:"missing_fields" binding:"omitempty,dive,oneof=age gender nationality"`
+
+	Include        string `form:"include"`
+	Sort           string `form:"sort"`
+	IncludeDeleted bool   `form:"include_deleted"`
+
+	AsOf *time.Time `form:"as_of" time_format:"2006-01-02T15:04:05Z07:00"`
+
+	Page int `form:"page"`
+	Size int `form:"size"`
+}
+
+// Includes reports whether the comma-separated include parameter names the
+// optional part of the person resource.
+func (f *Filter) Includes(part string) bool {
+	return Includes(f.Include, part)
+}
+
+// Includes reports whether the comma-separated include list names part.
+func Includes(include, part string) bool {
+	for _, name := range strings.Split(include, ",") {
+		if strings.TrimSpace(name) == part {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPersonRequest holds the options of the single-person read.
+type GetPersonRequest struct {
+	Include        string     `form:"include"`
+	IncludeDeleted bool       `form:"include_deleted"`
+	AsOf           *time.Time `form:"as_of" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// EnrichRequest holds the options of the re-enrichment endpoints.
+type EnrichRequest struct {
+	Force   bool   `form:"force"`
+	Include string `form:"include"`
 }
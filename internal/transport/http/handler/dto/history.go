@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"Effective/internal/domain"
+	"time"
+)
+
+type PersonHistoryResponse struct {
+	PersonID string                 `json:"person_id"`
+	Changes  []PersonChangeResponse `json:"changes"`
+}
+
+type PersonChangeResponse struct {
+	Version   int                 `json:"version"`
+	Action    string              `json:"action"`
+	Actor     string              `json:"actor"`
+	RequestID string              `json:"request_id,omitempty"`
+	CreatedAt time.Time           `json:"created_at"`
+	Diff      []FieldDiffResponse `json:"diff"`
+}
+
+type FieldDiffResponse struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// RevertRequest names the version a person is reverted to.
+type RevertRequest struct {
+	Version int `form:"version" binding:"required,min=1"`
+}
+
+func NewPersonHistoryResponse(personID string, changes []domain.PersonChange) PersonHistoryResponse {
+	response := PersonHistoryResponse{
+		PersonID: personID,
+		Changes:  make([]PersonChangeResponse, 0, len(changes)),
+	}
+	for _, change := range changes {
+		diff := make([]FieldDiffResponse, 0, len(change.Diff))
+		for _, d := range change.Diff {
+			diff = append(diff, FieldDiffResponse{Field: d.Field, Before: d.Before, After: d.After})
+		}
+		response.Changes = append(response.Changes, PersonChangeResponse{
+			Version:   change.Version,
+			Action:    change.Action,
+			Actor:     change.Actor,
+			RequestID: change.RequestID,
+			CreatedAt: change.CreatedAt,
+			Diff:      diff,
+		})
+	}
+	return response
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "Effective/pkg/names"
+
+type ParseNameRequest struct {
+	FullName string `json:"full_name" binding:"required,max=150"`
+}
+
+// ParsedNameResponse is a full name split into its parts. Order names the
+// order the parts were found in and Confidence, between 0 and 1, how sure
+// the parser is of it.
+type ParsedNameResponse struct {
+	Name       string  `json:"name"`
+	Surname    string  `json:"surname"`
+	Patronymic string  `json:"patronymic"`
+	Order      string  `json:"order"`
+	Confidence float64 `json:"confidence"`
+}
+
+func NewParsedNameResponse(name names.FullName) ParsedNameResponse {
+	return ParsedNameResponse{
+		Name:       name.Name,
+		Surname:    name.Surname,
+		Patronymic: name.Patronymic,
+		Order:      name.Order,
+		Confidence: name.Confidence,
+	}
+}
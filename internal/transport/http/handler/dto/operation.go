@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"Effective/internal/domain"
+	"time"
+)
+
+type EnrichmentOperationResponse struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	Total     int       `json:"total"`
+	Pending   int       `json:"pending"`
+	Running   int       `json:"running"`
+	Done      int       `json:"done"`
+	Failed    int       `json:"failed"`
+	Progress  float64   `json:"progress"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewEnrichmentOperationResponse(operation *domain.EnrichmentOperation) EnrichmentOperationResponse {
+	progress := 1.0
+	if operation.Total > 0 {
+		progress = float64(operation.Done+operation.Failed) / float64(operation.Total)
+	}
+
+	return EnrichmentOperationResponse{
+		ID:        operation.ID.String(),
+		Status:    operation.Status,
+		Total:     operation.Total,
+		Pending:   operation.Pending,
+		Running:   operation.Running,
+		Done:      operation.Done,
+		Failed:    operation.Failed,
+		Progress:  progress,
+		CreatedAt: operation.CreatedAt,
+		UpdatedAt: operation.UpdatedAt,
+	}
+}
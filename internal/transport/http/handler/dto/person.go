@@ -6,26 +6,188 @@ import (
 	"time"
 )
 
+// CreatePersonRequest takes names in any script; see names.Valid for the
+// accepted spelling. FullName may be given instead of the separate names and
+// is split by the name parser.
 type CreatePersonRequest struct {
-	Name    string `json:"name" binding:"required,min=2,max=50,alpha"`
-	Surname string `json:"surname" binding:"required,min=2,max=50,alpha"`
+	Name       string `json:"name" binding:"required_without=FullName,omitempty,min=2,max=50,personname"`
+	Surname    string `json:"surname" binding:"required_without=FullName,omitempty,min=2,max=50,personname"`
+	Patronymic string `json:"patronymic" binding:"omitempty,min=2,max=50,personname"`
+	FullName   string `json:"full_name" binding:"omitempty,max=150,excluded_with=Name Surname Patronymic"`
+	CountryID  string `json:"country_id" binding:"omitempty,country"`
+}
+
+// CreatePersonResponse is returned for a created person. UnenrichedFields
+// lists the fields that could not be enriched, with the reasons in
+// EnrichmentErrors. NameConfidence is the confidence of the split of a full
+// name.
+type CreatePersonResponse struct {
+	ID               string            `json:"id"`
+	EnrichmentStatus string            `json:"enrichment_status"`
+	UnenrichedFields []string          `json:"unenriched_fields,omitempty"`
+	EnrichmentErrors map[string]string `json:"enrichment_errors,omitempty"`
+	NameConfidence   *float64          `json:"name_confidence,omitempty"`
+}
+
+func NewCreatePersonResponse(person *domain.Person) CreatePersonResponse {
+	return CreatePersonResponse{
+		ID:               person.ID.String(),
+		EnrichmentStatus: person.EnrichmentStatus,
+		UnenrichedFields: unenrichedFields(person),
+		EnrichmentErrors: person.EnrichmentErrors,
+	}
+}
+
+// EnrichmentResponse maps each enriched field to its enrichment state.
+type EnrichmentResponse map[string]FieldEnrichmentResponse
+
+// FieldEnrichmentResponse is the enrichment state of one field.
+type FieldEnrichmentResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
 }
 
 type PersonResponse struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Surname     string    `json:"surname"`
-	Age         int       `json:"age"`
-	Gender      string    `json:"gender"`
-	Nationality string    `json:"nationality"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	DeletedAt   time.Time `json:"deleted_at"`
+	ID                     string                `json:"id"`
+	Name                   string                `json:"name"`
+	Surname                string                `json:"surname"`
+	Patronymic             string                `json:"patronymic"`
+	NameLatin              string                `json:"name_latin"`
+	SurnameLatin           string                `json:"surname_latin"`
+	PatronymicLatin        string                `json:"patronymic_latin"`
+	Age                    int                   `json:"age"`
+	AgeCount               int                   `json:"age_count"`
+	AgeSource              string                `json:"age_source"`
+	Gender                 string                `json:"gender"`
+	GenderProbability      float64               `json:"gender_probability"`
+	GenderCount            int                   `json:"gender_count"`
+	GenderSource           string                `json:"gender_source"`
+	Nationality            string                `json:"nationality"`
+	NationalityProbability float64               `json:"nationality_probability"`
+	NationalitySource      string                `json:"nationality_source"`
+	Nationalities          []NationalityResponse `json:"nationalities,omitempty"`
+	Provenance             ProvenanceResponse    `json:"provenance,omitempty"`
+	EnrichmentStatus       string                `json:"enrichment_status"`
+	Enrichment             EnrichmentResponse    `json:"enrichment"`
+	UnenrichedFields       []string              `json:"unenriched_fields,omitempty"`
+	EnrichedAt             *time.Time            `json:"enriched_at,omitempty"`
+	Version                int                   `json:"version"`
+	CreatedAt              time.Time             `json:"created_at"`
+	UpdatedAt              time.Time             `json:"updated_at"`
+	DeletedAt              *time.Time            `json:"deleted_at,omitempty"`
+}
+
+// ProvenanceResponse maps each field to where its value came from.
+type ProvenanceResponse map[string]FieldProvenanceResponse
+
+type FieldProvenanceResponse struct {
+	Source    string    `json:"source"`
+	Actor     string    `json:"actor"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Locked    bool      `json:"locked"`
+}
+
+func newProvenanceResponse(provenance map[string]domain.FieldProvenance) ProvenanceResponse {
+	if len(provenance) == 0 {
+		return nil
+	}
+
+	resp := make(ProvenanceResponse, len(provenance))
+	for field, p := range provenance {
+		resp[field] = FieldProvenanceResponse{
+			Source:    p.Source,
+			Actor:     p.Actor,
+			UpdatedAt: p.UpdatedAt,
+			Locked:    p.Locked,
+		}
+	}
+	return resp
+}
+
+type NationalityResponse struct {
+	CountryID   string  `json:"country_id"`
+	Probability float64 `json:"probability"`
+}
+
+func NewPersonResponse(person *domain.Person) PersonResponse {
+	var nationalities []NationalityResponse
+	for _, candidate := range person.Nationalities {
+		nationalities = append(nationalities, NationalityResponse{
+			CountryID:   candidate.CountryID,
+			Probability: candidate.Probability,
+		})
+	}
+
+	return PersonResponse{
+		ID:                     person.ID.String(),
+		Name:                   person.Name,
+		Surname:                person.Surname,
+		Patronymic:             person.Patronymic,
+		NameLatin:              person.NameLatin,
+		SurnameLatin:           person.SurnameLatin,
+		PatronymicLatin:        person.PatronymicLatin,
+		Age:                    person.Age,
+		AgeCount:               person.AgeCount,
+		AgeSource:              person.AgeSource,
+		Gender:                 person.Gender,
+		GenderProbability:      person.GenderProbability,
+		GenderCount:            person.GenderCount,
+		GenderSource:           person.GenderSource,
+		Nationality:            person.Nationality,
+		NationalityProbability: person.NationalityProbability,
+		NationalitySource:      person.NationalitySource,
+		Nationalities:          nationalities,
+		Provenance:             newProvenanceResponse(person.Provenance),
+		EnrichmentStatus:       person.EnrichmentStatus,
+		Enrichment:             fieldEnrichment(person),
+		UnenrichedFields:       unenrichedFields(person),
+		EnrichedAt:             person.EnrichedAt,
+		Version:                person.Version,
+		CreatedAt:              person.CreatedAt,
+		UpdatedAt:              person.UpdatedAt,
+		DeletedAt:              person.DeletedAt,
+	}
+}
+
+func fieldEnrichment(person *domain.Person) EnrichmentResponse {
+	fields := make(EnrichmentResponse, len(domain.EnrichedFields))
+	for _, field := range domain.EnrichedFields {
+		status := domain.EnrichmentCompleted
+		reason, failed := person.EnrichmentErrors[field]
+		switch {
+		case failed:
+			status = domain.EnrichmentFailed
+		case person.EnrichmentStatus == domain.EnrichmentPending:
+			status = domain.EnrichmentPending
+		}
+		fields[field] = FieldEnrichmentResponse{Status: status, Error: reason}
+	}
+	return fields
+}
+
+// unenrichedFields lists the fields that are not enriched, in a fixed order.
+func unenrichedFields(person *domain.Person) []string {
+	var fields []string
+	for _, field := range domain.EnrichedFields {
+		if _, failed := person.EnrichmentErrors[field]; failed || person.EnrichmentStatus == domain.EnrichmentPending {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func NewPersonsResponse(persons []domain.Person) []PersonResponse {
+	resp := make([]PersonResponse, 0, len(persons))
+	for i := range persons {
+		resp = append(resp, NewPersonResponse(&persons[i]))
+	}
+	return resp
 }
 
 type UpdatePersonRequest struct {
-	Name        string `json:"name"`
-	Surname     string `json:"surname"`
+	Name        string `json:"name" binding:"omitempty,min=2,max=50,personname"`
+	Surname     string `json:"surname" binding:"omitempty,min=2,max=50,personname"`
+	Patronymic  string `json:"patronymic" binding:"omitempty,min=2,max=50,personname"`
 	Age         int    `json:"age"`
 	Gender      string `json:"gender"`
 	Nationality string `json:"nationality"`
@@ -44,6 +206,10 @@ func (req *UpdatePersonRequest) NewPerson(person *domain.Person) error {
 		person.Surname = req.Surname
 	}
 
+	if req.Patronymic != "" {
+		person.Patronymic = req.Patronymic
+	}
+
 	if req.Age != 0 {
 		person.Age = req.Age
 	}
@@ -59,6 +225,40 @@ func (req *UpdatePersonRequest) NewPerson(person *domain.Person) error {
 	return nil
 }
 
+// PersonDocument is the editable part of a person: the whole of it is
+// replaced by PUT and patched by the merge and JSON patches of PATCH. An
+// empty or zero field clears the stored value.
+type PersonDocument struct {
+	Name        string `json:"name" binding:"required,min=2,max=50,personname"`
+	Surname     string `json:"surname" binding:"required,min=2,max=50,personname"`
+	Patronymic  string `json:"patronymic" binding:"omitempty,min=2,max=50,personname"`
+	Age         int    `json:"age" binding:"min=0"`
+	Gender      string `json:"gender"`
+	Nationality string `json:"nationality"`
+}
+
+// NewPersonDocument returns the editable fields of person.
+func NewPersonDocument(person *domain.Person) PersonDocument {
+	return PersonDocument{
+		Name:        person.Name,
+		Surname:     person.Surname,
+		Patronymic:  person.Patronymic,
+		Age:         person.Age,
+		Gender:      person.Gender,
+		Nationality: person.Nationality,
+	}
+}
+
+// Apply sets every editable field of person from the document.
+func (doc *PersonDocument) Apply(person *domain.Person) {
+	person.Name = doc.Name
+	person.Surname = doc.Surname
+	person.Patronymic = doc.Patronymic
+	person.Age = doc.Age
+	person.Gender = doc.Gender
+	person.Nationality = doc.Nationality
+}
+
 type UpdatePersonResponse struct {
 	ID          string    `json:"id"`
 	Name        string    `json:"name"`
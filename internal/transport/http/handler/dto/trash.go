@@ -0,0 +1,11 @@
+package dto
+
+import "time"
+
+type PurgeRequest struct {
+	OlderThan time.Duration `form:"older_than" binding:"omitempty,min=0"`
+}
+
+type PurgeResponse struct {
+	Purged int64 `json:"purged"`
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"Effective/internal/service"
+	"Effective/pkg/logger"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type EnrichmentHandler struct {
+	enricher  *service.Enricher
+	providers *service.ProviderChain
+	logger    *logger.Logger
+}
+
+type EnrichmentStatusResponse struct {
+	Providers service.ProviderChainStatus `json:"providers"`
+	Breakers  []service.BreakerStatus     `json:"breakers"`
+}
+
+func NewEnrichmentHandler(
+	enricher *service.Enricher,
+	providers *service.ProviderChain,
+	logger *logger.Logger,
+) *EnrichmentHandler {
+	return &EnrichmentHandler{
+		enricher:  enricher,
+		providers: providers,
+		logger:    logger,
+	}
+}
+
+// GetStatus godoc
+// @Summary Get enrichment provider status
+// @Description Provider chains and circuit breaker state of every enrichment provider
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} handler.EnrichmentStatusResponse
+// @Failure 403 {object} handler.ErrResponse
+// @Security AdminToken
+// @Router /admin/enrichment/status [get]
+func (h *EnrichmentHandler) GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, EnrichmentStatusResponse{
+		Providers: h.providers.Status(),
+		Breakers:  h.enricher.BreakerStatus(),
+	})
+}
+
+// ReloadDatasets godoc
+// @Summary Reload offline enrichment datasets
+// @Description Re-read every local name dataset used by the offline provider
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} service.DatasetStatus
+// @Failure 403 {object} handler.ErrResponse
+// @Failure 500 {object} handler.ErrResponse
+// @Security AdminToken
+// @Router /admin/enrichment/datasets/reload [post]
+func (h *EnrichmentHandler) ReloadDatasets(c *gin.Context) {
+	statuses, err := h.providers.ReloadDatasets()
+	if err != nil {
+		h.logger.Error("failed to reload datasets", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Failed to reload datasets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, statuses)
+}
@@ -1,5 +1,41 @@
 package handler
 
+import "Effective/internal/service"
+
 type ErrResponse struct {
 	Error string `json:"error"`
-}
\ No newline at end of file
+}
+
+// EnrichmentErrorResponse lists the reason each field could not be enriched.
+type EnrichmentErrorResponse struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields"`
+}
+
+func newEnrichmentErrorResponse(err *service.EnrichmentError) EnrichmentErrorResponse {
+	fields := make(map[string]string, len(err.Fields))
+	for field, reason := range err.Fields {
+		fields[field] = reason.Error()
+	}
+	return EnrichmentErrorResponse{Error: "Enrichment failed", Fields: fields}
+}
+
+// ValidationErrorResponse lists every field that breaks a rule.
+type ValidationErrorResponse struct {
+	Error  string               `json:"error"`
+	Fields []FieldErrorResponse `json:"fields"`
+}
+
+type FieldErrorResponse struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func newValidationErrorResponse(err *service.ValidationError) ValidationErrorResponse {
+	fields := make([]FieldErrorResponse, 0, len(err.Fields))
+	for _, field := range err.Fields {
+		fields = append(fields, FieldErrorResponse{Field: field.Field, Code: field.Code, Message: field.Message})
+	}
+	return ValidationErrorResponse{Error: "Validation failed", Fields: fields}
+}
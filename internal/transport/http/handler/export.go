@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"Effective/internal/domain"
+	"Effective/internal/service"
+	"Effective/internal/transport/http/handler/dto"
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var exportHeader = []string{
+	"id", "name", "surname", "patronymic", "age", "gender", "nationality", "enrichment_status", "created_at", "updated_at",
+}
+
+// ExportPersons godoc
+// @Summary Export persons as CSV
+// @Description Export every person matching the filter of GET /persons as CSV. Pagination is ignored
+// @Tags Person
+// @Produce text/csv
+// @Param name query string false "Name"
+// @Param surname query string false "Surname"
+// @Param patronymic query string false "Patronymic"
+// @Param gender query string false "Gender"
+// @Param nationality query string false "Nationality"
+// @Param sort query string false "Comma-separated sort fields, descending with a - prefix"
+// @Param as_of query string false "RFC 3339 time to export the persons at"
+// @Param include_deleted query bool false "Also export persons in the trash; needs the admin token"
+// @Param X-Admin-Token header string false "Admin token"
+// @Success 200 {string} string "CSV file"
+// @Failure 400 {object} handler.ErrResponse
+// @Failure 403 {object} handler.ErrResponse
+// @Failure 500 {object} handler.ErrResponse
+// @Router /persons/export [get]
+func (h *PersonHandler) ExportPersons(c *gin.Context) {
+	var req dto.Filter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("Invalid export request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid request body"})
+		return
+	}
+	if req.IncludeDeleted && !isAdmin(c) {
+		c.JSON(http.StatusForbidden, ErrResponse{Error: "include_deleted requires the admin token"})
+		return
+	}
+
+	w := csv.NewWriter(c.Writer)
+	started := false
+	err := h.service.ExportPersons(c.Request.Context(), &req, func(person *domain.Person) error {
+		if !started {
+			started = true
+			c.Header("Content-Type", "text/csv; charset=utf-8")
+			c.Header("Content-Disposition", `attachment; filename="persons.csv"`)
+			c.Status(http.StatusOK)
+			if err := w.Write(exportHeader); err != nil {
+				return err
+			}
+		}
+		return w.Write(exportRecord(person))
+	})
+	if err == nil && !started {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="persons.csv"`)
+		c.Status(http.StatusOK)
+		err = w.Write(exportHeader)
+	}
+	if err != nil {
+		h.logger.Error("failed to export persons", zap.Error(err))
+		if started {
+			return
+		}
+		if errors.Is(err, service.ErrInvalidFilter) {
+			c.JSON(http.StatusBadRequest, ErrResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		return
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		h.logger.Error("failed to write export", zap.Error(err))
+	}
+}
+
+func exportRecord(person *domain.Person) []string {
+	return []string{
+		person.ID.String(),
+		person.Name,
+		person.Surname,
+		person.Patronymic,
+		strconv.Itoa(person.Age),
+		person.Gender,
+		person.Nationality,
+		person.EnrichmentStatus,
+		person.CreatedAt.Format(time.RFC3339),
+		person.UpdatedAt.Format(time.RFC3339),
+	}
+}
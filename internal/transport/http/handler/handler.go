@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"Effective/internal/domain"
+	"Effective/internal/repository"
 	"Effective/internal/service"
 	"Effective/internal/transport/http/handler/dto"
 	"Effective/pkg/logger"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -27,41 +31,173 @@ func NewPersonHandler(
 }
 // CreatePerson godoc
 // @Summary Create a new person
-// @Description Create a new person with the provided details
+// @Description Create a new person with the provided details. In async mode the person is enriched in the background and 202 is returned. Fields that could not be enriched are listed in unenriched_fields; under the strict failure policy the person is not created and 502 is returned
 // @Tags Person
 // @Accept json
 // @Produce json
-// @Param person body dto.CreatePersonRequest true "Person details"
-// @Success 200 {string} string "ID of the created person"
+// @Param person body dto.CreatePersonRequest true "Person details, with either name and surname or full_name"
+// @Success 200 {object} dto.CreatePersonResponse "Created person"
+// @Success 202 {object} dto.CreatePersonResponse "Person accepted for enrichment"
 // @Failure 400 {object} handler.ErrResponse
+// @Failure 422 {object} handler.ValidationErrorResponse
+// @Failure 502 {object} handler.EnrichmentErrorResponse
 // @Failure 500 {object} handler.ErrResponse
 // @Router /person [post]
 func (h *PersonHandler) CreatePerson(c *gin.Context) {
 	var req dto.CreatePersonRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Error("Invalid register request", zap.Error(err))
-		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid request body"})
+		writeBindingError(c, err)
 		return
 	}
 
-	id, err := h.service.CreatePerson(c.Request.Context(), &req)
+	var confidence *float64
+	if req.FullName != "" {
+		name, err := h.service.SplitFullName(&req)
+		if err != nil {
+			h.logger.Error("Invalid full name", zap.Error(err))
+			c.JSON(http.StatusBadRequest, ErrResponse{Error: err.Error()})
+			return
+		}
+		if err := binding.Validator.ValidateStruct(&req); err != nil {
+			h.logger.Error("Invalid full name", zap.Error(err))
+			writeBindingError(c, err)
+			return
+		}
+		confidence = &name.Confidence
+	}
+
+	person, err := h.service.CreatePerson(c.Request.Context(), &req)
 	if err != nil {
 		h.logger.Error("Registration failed", zap.Error(err))
+		var (
+			enrichErr     *service.EnrichmentError
+			validationErr *service.ValidationError
+		)
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusUnprocessableEntity, newValidationErrorResponse(validationErr))
+			return
+		}
+		if errors.As(err, &enrichErr) {
+			c.JSON(http.StatusBadGateway, newEnrichmentErrorResponse(enrichErr))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Person create successfully", zap.String("id", person.ID.String()), zap.String("enrichment_status", person.EnrichmentStatus))
+	resp := dto.NewCreatePersonResponse(person)
+	resp.NameConfidence = confidence
+	if person.EnrichmentStatus == domain.EnrichmentPending {
+		c.JSON(http.StatusAccepted, resp)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+// GetPerson godoc
+// @Summary Get a person
+// @Description Get a person by ID. Its nationality candidates and provenance are returned when named in include. With as_of the person is returned as it was at that time, without provenance. The response carries ETag and Last-Modified headers; a matching If-None-Match or an If-Modified-Since at or after the last change is answered with 304
+// @Tags Person
+// @Produce json
+// @Param id path string true "Person ID"
+// @Param include_deleted query bool false "Also return a person in the trash; needs the admin token"
+// @Param X-Admin-Token header string false "Admin token"
+// @Param as_of query string false "RFC 3339 time to read the person at"
+// @Param include query string false "Optional parts to include: nationalities, provenance"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Param If-Modified-Since header string false "Date of a cached copy"
+// @Success 200 {object} dto.PersonResponse
+// @Success 304 "Not modified"
+// @Failure 400 {object} handler.ErrResponse
+// @Failure 403 {object} handler.ErrResponse
+// @Failure 404 {object} handler.ErrResponse
+// @Failure 500 {object} handler.ErrResponse
+// @Router /person/{id} [get]
+func (h *PersonHandler) GetPerson(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Error("failed to parse id", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid id"})
+		return
+	}
+
+	var req dto.GetPersonRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("Invalid get request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid request body"})
+		return
+	}
+	if req.IncludeDeleted && !isAdmin(c) {
+		c.JSON(http.StatusForbidden, ErrResponse{Error: "include_deleted requires the admin token"})
+		return
+	}
+
+	var person *domain.Person
+	if req.AsOf != nil {
+		person, err = h.service.GetPersonAsOf(c.Request.Context(), id, *req.AsOf, req.IncludeDeleted)
+	} else {
+		person, err = h.service.GetPerson(c.Request.Context(), id, req.IncludeDeleted)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, ErrResponse{Error: "Person not found"})
+			return
+		}
+		h.logger.Error("failed to get person", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
 		return
 	}
 
-	idStr := id.String()
-	h.logger.Info("Person create successfully", zap.String("id", idStr))
-	c.JSON(http.StatusOK, idStr)
+	if !dto.Includes(req.Include, dto.IncludeNationalities) {
+		person.Nationalities = nil
+	}
+	if !dto.Includes(req.Include, dto.IncludeProvenance) {
+		person.Provenance = nil
+	}
+	if err := writeConditional(c, personETag(person.Version), person.UpdatedAt, dto.NewPersonResponse(person)); err != nil {
+		h.logger.Error("failed to write person", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+	}
+}
+// ParseName godoc
+// @Summary Split a full name
+// @Description Split a free-form full name such as "Ivanov Ivan Ivanovich" or "Ivan I. Ivanov" into name, surname and patronymic. The order is detected from patronymic and surname suffixes and name dictionaries, with a confidence between 0 and 1
+// @Tags Person
+// @Accept json
+// @Produce json
+// @Param name body dto.ParseNameRequest true "Full name"
+// @Success 200 {object} dto.ParsedNameResponse
+// @Failure 400 {object} handler.ErrResponse
+// @Router /person/parse [post]
+func (h *PersonHandler) ParseName(c *gin.Context) {
+	var req dto.ParseNameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Invalid parse request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid request body"})
+		return
+	}
+
+	name, err := h.service.ParseFullName(req.FullName)
+	if err != nil {
+		h.logger.Info("Failed to parse full name", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewParsedNameResponse(name))
 }
 // DeletePerson godoc
 // @Summary Delete a person
-// @Description Delete a person by ID
+// @Description Delete a person by ID. With If-Match the person must still carry that ETag, or 412 is returned with its current representation
 // @Tags Person
 // @Param id path string true "Person ID"
+// @Param If-Match header string false "ETag the person must still have"
 // @Success 200 {string} string "Successfully deleted"
 // @Failure 400 {object} handler.ErrResponse
+// @Failure 404 {object} handler.ErrResponse
+// @Failure 412 {object} dto.PersonResponse "Current person"
 // @Failure 500 {object} handler.ErrResponse
 // @Router /person/{id} [delete]
 func (h *PersonHandler) DeletePerson(c *gin.Context) {
@@ -73,8 +209,18 @@ func (h *PersonHandler) DeletePerson(c *gin.Context) {
 		return
 	}
 
-	ok, err := h.service.DeletePerson(c.Request.Context(), id)
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		h.preconditionFailed(c, id)
+		return
+	}
+
+	ok, err = h.service.DeletePerson(c.Request.Context(), id, version)
 	if err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			h.preconditionFailed(c, id)
+			return
+		}
 		h.logger.Error("failed to get persons", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
 		return
@@ -91,14 +237,21 @@ func (h *PersonHandler) DeletePerson(c *gin.Context) {
 }
 // UpdatePerson godoc
 // @Summary Update a person
-// @Description Update a person by ID
+// @Description Update a person by ID. Changed fields are recorded as manual values and locked against re-enrichment. A plain JSON body only sets its non-empty fields; an application/merge-patch+json body (RFC 7396) also clears fields set to null, and an application/json-patch+json body (RFC 6902) applies its operations in order, answering a failed test with 409. With If-Match the person must still carry that ETag, or 412 is returned with its current representation; the new ETag is returned in the ETag header
 // @Tags Person
-// @Accept json
+// @Accept json,application/merge-patch+json,application/json-patch+json
 // @Produce json
 // @Param id path string true "Person ID"
+// @Param X-Actor header string false "Caller the change is attributed to"
+// @Param If-Match header string false "ETag the person must still have"
 // @Param person body dto.UpdatePersonRequest true "Person details to update"
 // @Success 200
 // @Failure 400 {object} handler.ErrResponse
+// @Failure 404 {object} handler.ErrResponse
+// @Failure 409 {object} handler.ErrResponse
+// @Failure 412 {object} dto.PersonResponse "Current person"
+// @Failure 415 {object} handler.ErrResponse
+// @Failure 422 {object} handler.ValidationErrorResponse
 // @Failure 500 {object} handler.ErrResponse
 // @Router /person/{id} [patch]
 func (h *PersonHandler) UpdatePerson(c *gin.Context) {
@@ -110,34 +263,277 @@ func (h *PersonHandler) UpdatePerson(c *gin.Context) {
 		return
 	}
 
+	switch contentType := c.ContentType(); contentType {
+	case mimeMergePatch, mimeJSONPatch:
+		h.patchPerson(c, id, contentType)
+		return
+	case binding.MIMEJSON, "":
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, ErrResponse{Error: "Unsupported content type " + contentType})
+		return
+	}
+
 	var req dto.UpdatePersonRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Error("Invalid register request", zap.Error(err))
-		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid request body"})
+		writeBindingError(c, err)
 		return
 	}
 
-	if err := h.service.UpdatePerson(c.Request.Context(), id, &req); err != nil {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		h.preconditionFailed(c, id)
+		return
+	}
 
+	person, err := h.service.UpdatePerson(c.Request.Context(), id, &req, version)
+	if err != nil {
 		h.logger.Error("failed to update person", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		var validationErr *service.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusUnprocessableEntity, newValidationErrorResponse(validationErr))
+		case errors.Is(err, repository.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrResponse{Error: "Person not found"})
+		case errors.Is(err, repository.ErrVersionConflict) && version != nil:
+			h.preconditionFailed(c, id)
+		case errors.Is(err, repository.ErrVersionConflict):
+			c.JSON(http.StatusConflict, ErrResponse{Error: "Person changed while it was updated"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		}
 		return
 	}
 
 	h.logger.Info("Person updated successfully", zap.String("id", idStr))
+	c.Header(headerETag, personETag(person.Version))
 
 	c.JSON(http.StatusOK, true)
 }
+// ReplacePerson godoc
+// @Summary Replace a person
+// @Description Replace every editable field of a person. Fields left out are cleared; name and surname are required. Changed fields are recorded as manual values and locked against re-enrichment. With If-Match the person must still carry that ETag, or 412 is returned with its current representation
+// @Tags Person
+// @Accept json
+// @Produce json
+// @Param id path string true "Person ID"
+// @Param X-Actor header string false "Caller the change is attributed to"
+// @Param If-Match header string false "ETag the person must still have"
+// @Param person body dto.PersonDocument true "Person"
+// @Success 200 {object} dto.PersonResponse
+// @Failure 400 {object} handler.ErrResponse
+// @Failure 404 {object} handler.ErrResponse
+// @Failure 409 {object} handler.ErrResponse
+// @Failure 412 {object} dto.PersonResponse "Current person"
+// @Failure 422 {object} handler.ValidationErrorResponse
+// @Failure 500 {object} handler.ErrResponse
+// @Router /person/{id} [put]
+func (h *PersonHandler) ReplacePerson(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Error("failed to parse id", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid id"})
+		return
+	}
+
+	var req dto.PersonDocument
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Invalid replace request", zap.Error(err))
+		writeBindingError(c, err)
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		h.preconditionFailed(c, id)
+		return
+	}
+
+	person, err := h.service.ReplacePerson(c.Request.Context(), id, &req, version)
+	if err != nil {
+		h.logger.Error("failed to replace person", zap.Error(err))
+		var validationErr *service.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusUnprocessableEntity, newValidationErrorResponse(validationErr))
+		case errors.Is(err, repository.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrResponse{Error: "Person not found"})
+		case errors.Is(err, repository.ErrVersionConflict) && version != nil:
+			h.preconditionFailed(c, id)
+		case errors.Is(err, repository.ErrVersionConflict):
+			c.JSON(http.StatusConflict, ErrResponse{Error: "Person changed while it was replaced"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		}
+		return
+	}
+
+	h.logger.Info("Person replaced successfully", zap.String("id", idStr))
+	c.Header(headerETag, personETag(person.Version))
+	c.JSON(http.StatusOK, dto.NewPersonResponse(person))
+}
+// EnrichPerson godoc
+// @Summary Re-enrich a person
+// @Description Re-run enrichment for one person and save the fields that could be enriched, following the failure policy. Fields locked by a manual change are skipped unless force is set
+// @Tags Person
+// @Produce json
+// @Param id path string true "Person ID"
+// @Param force query bool false "Also enrich fields locked by a manual change"
+// @Param include query string false "Optional parts to include: provenance"
+// @Success 200 {object} dto.PersonResponse
+// @Failure 400 {object} handler.ErrResponse
+// @Failure 404 {object} handler.ErrResponse
+// @Failure 409 {object} handler.ErrResponse
+// @Failure 502 {object} handler.EnrichmentErrorResponse
+// @Failure 500 {object} handler.ErrResponse
+// @Router /person/{id}/enrich [post]
+func (h *PersonHandler) EnrichPerson(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Error("failed to parse id", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid id"})
+		return
+	}
+
+	var req dto.EnrichRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("Invalid enrich request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid request body"})
+		return
+	}
+
+	person, err := h.service.EnrichPerson(c.Request.Context(), id, req.Force)
+	if err != nil {
+		h.logger.Error("failed to enrich person", zap.Error(err))
+		var enrichErr *service.EnrichmentError
+		switch {
+		case errors.Is(err, repository.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrResponse{Error: "Person not found"})
+		case errors.Is(err, repository.ErrVersionConflict):
+			c.JSON(http.StatusConflict, ErrResponse{Error: "Person changed during enrichment, retry"})
+		case errors.As(err, &enrichErr):
+			c.JSON(http.StatusBadGateway, newEnrichmentErrorResponse(enrichErr))
+		default:
+			c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		}
+		return
+	}
+
+	h.logger.Info("Person enriched successfully", zap.String("id", idStr))
+	if !dto.Includes(req.Include, dto.IncludeProvenance) {
+		person.Provenance = nil
+	}
+	c.JSON(http.StatusOK, dto.NewPersonResponse(person))
+}
+// EnrichPersons godoc
+// @Summary Re-enrich persons in bulk
+// @Description Queue re-enrichment of every person matching the filter of GET /persons and return the operation tracking it. Pagination is ignored and fields locked by a manual change are skipped unless force is set
+// @Tags Person
+// @Produce json
+// @Param force query bool false "Also enrich fields locked by a manual change"
+// @Param min_age_count query int false "Minimum number of samples behind the age"
+// @Param min_gender_probability query number false "Minimum gender probability (0-1)"
+// @Param min_gender_count query int false "Minimum number of samples behind the gender"
+// @Param min_nationality_probability query number false "Minimum nationality probability (0-1)"
+// @Param candidate_country query string false "Country any nationality candidate must match (ISO 3166-1 alpha-2)"
+// @Param min_candidate_probability query number false "Minimum probability of the candidate country (0-1)"
+// @Param enrichment_status query string false "Enrichment status: pending, completed, partial or failed"
+// @Param stale_since query string false "Only persons not enriched since this time (RFC 3339)"
+// @Param missing_fields query []string false "Only persons missing any of these fields: age, gender, nationality" collectionFormat(multi)
+// @Success 202 {object} dto.EnrichmentOperationResponse
+// @Failure 400 {object} handler.ErrResponse
+// @Failure 500 {object} handler.ErrResponse
+// @Router /persons/enrich [post]
+func (h *PersonHandler) EnrichPersons(c *gin.Context) {
+	var (
+		req     dto.Filter
+		options dto.EnrichRequest
+	)
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("Invalid enrich request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid request body"})
+		return
+	}
+	if err := c.ShouldBindQuery(&options); err != nil {
+		h.logger.Error("Invalid enrich request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid request body"})
+		return
+	}
+
+	operation, err := h.service.EnrichPersons(c.Request.Context(), &req, options.Force)
+	if err != nil {
+		h.logger.Error("failed to enrich persons", zap.Error(err))
+		if errors.Is(err, service.ErrInvalidFilter) {
+			c.JSON(http.StatusBadRequest, ErrResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		return
+	}
+
+	c.Header("Location", "/api/v1/operations/"+operation.ID.String())
+	c.JSON(http.StatusAccepted, dto.NewEnrichmentOperationResponse(operation))
+}
+// GetEnrichmentOperation godoc
+// @Summary Get a re-enrichment operation
+// @Description Progress of a bulk re-enrichment
+// @Tags Person
+// @Produce json
+// @Param id path string true "Operation ID"
+// @Success 200 {object} dto.EnrichmentOperationResponse
+// @Failure 400 {object} handler.ErrResponse
+// @Failure 404 {object} handler.ErrResponse
+// @Failure 500 {object} handler.ErrResponse
+// @Router /operations/{id} [get]
+func (h *PersonHandler) GetEnrichmentOperation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.logger.Error("failed to parse id", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid id"})
+		return
+	}
+
+	operation, err := h.service.GetEnrichmentOperation(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to get enrichment operation", zap.Error(err))
+		if errors.Is(err, repository.ErrOperationNotFound) {
+			c.JSON(http.StatusNotFound, ErrResponse{Error: "Operation not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewEnrichmentOperationResponse(operation))
+}
 // GetPersons godoc
 // @Summary Get a list of persons
-// @Description Retrieve a paginated list of persons
+// @Description Retrieve a paginated list of persons. With as_of the persons are listed as they were at that time; provenance is then not available
 // @Tags Person
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number (default: 1)" default(1)
 // @Param size query int false "Page size (default: 10)" default(10)
+// @Param min_age_count query int false "Minimum number of samples behind the age"
+// @Param min_gender_probability query number false "Minimum gender probability (0-1)"
+// @Param min_gender_count query int false "Minimum number of samples behind the gender"
+// @Param min_nationality_probability query number false "Minimum nationality probability (0-1)"
+// @Param candidate_country query string false "Country any nationality candidate must match (ISO 3166-1 alpha-2)"
+// @Param min_candidate_probability query number false "Minimum probability of the candidate country (0-1)"
+// @Param enrichment_status query string false "Enrichment status: pending, completed, partial or failed"
+// @Param stale_since query string false "Only persons not enriched since this time (RFC 3339)"
+// @Param missing_fields query []string false "Only persons missing any of these fields: age, gender, nationality" collectionFormat(multi)
+// @Param include query string false "Optional parts to include: nationalities, provenance"
+// @Param patronymic query string false "Patronymic"
+// @Param sort query string false "Comma-separated sort fields, descending with a - prefix: name, surname, patronymic, age, gender, nationality, created_at, updated_at, deleted_at"
+// @Param include_deleted query bool false "Also list persons in the trash; needs the admin token"
+// @Param X-Admin-Token header string false "Admin token"
+// @Param as_of query string false "RFC 3339 time to list the persons at"
 // @Success 200 {array} dto.PersonResponse
 // @Failure 400 {object} handler.ErrResponse
+// @Failure 403 {object} handler.ErrResponse
 // @Failure 500 {object} handler.ErrResponse
 // @Router /persons [get]
 func (h *PersonHandler) GetPersons(c *gin.Context) {
@@ -147,13 +543,223 @@ func (h *PersonHandler) GetPersons(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid request body"})
 		return
 	}
+	if req.IncludeDeleted && !isAdmin(c) {
+		c.JSON(http.StatusForbidden, ErrResponse{Error: "include_deleted requires the admin token"})
+		return
+	}
 
 	filterPerson, err := h.service.GetPersonWithFilter(c.Request.Context(), &req)
 	if err != nil {
 		h.logger.Error("failed to get persons", zap.Error(err))
+		if errors.Is(err, service.ErrInvalidFilter) {
+			c.JSON(http.StatusBadRequest, ErrResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewPersonsResponse(*filterPerson))
+}
+// RestorePerson godoc
+// @Summary Restore a deleted person
+// @Description Take a person out of the trash
+// @Tags Trash
+// @Produce json
+// @Param id path string true "Person ID"
+// @Success 200 {object} dto.PersonResponse
+// @Failure 400 {object} handler.ErrResponse
+// @Failure 404 {object} handler.ErrResponse "Person is not in the trash"
+// @Failure 500 {object} handler.ErrResponse
+// @Router /person/{id}/restore [post]
+func (h *PersonHandler) RestorePerson(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Error("failed to parse id", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid id"})
+		return
+	}
+
+	person, err := h.service.RestorePerson(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, ErrResponse{Error: "Person not found in trash"})
+			return
+		}
+		h.logger.Error("failed to restore person", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Person restored successfully", zap.String("id", idStr))
+	c.Header(headerETag, personETag(person.Version))
+	c.JSON(http.StatusOK, dto.NewPersonResponse(person))
+}
+// GetHistory godoc
+// @Summary Get the history of a person
+// @Description Get every change made to a person, newest first, with the actor, the request id and the fields it changed. Deleted persons keep their history
+// @Tags Person
+// @Produce json
+// @Param id path string true "Person ID"
+// @Success 200 {object} dto.PersonHistoryResponse
+// @Failure 400 {object} handler.ErrResponse
+// @Failure 404 {object} handler.ErrResponse
+// @Failure 500 {object} handler.ErrResponse
+// @Router /person/{id}/history [get]
+func (h *PersonHandler) GetHistory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Error("failed to parse id", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid id"})
+		return
+	}
+
+	changes, err := h.service.GetHistory(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, ErrResponse{Error: "Person not found"})
+			return
+		}
+		h.logger.Error("failed to get person history", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewPersonHistoryResponse(idStr, changes))
+}
+// RevertPerson godoc
+// @Summary Revert a person to an earlier version
+// @Description Set the names and attributes of a person back to the values it had at version and save them as a new change. Reverted names are locked as manual values; reverted attributes keep the source they had. With If-Match the person must still carry that ETag, or 412 is returned with its current representation
+// @Tags Person
+// @Produce json
+// @Param id path string true "Person ID"
+// @Param version query int true "Version to revert to"
+// @Param X-Actor header string false "Caller the change is attributed to"
+// @Param If-Match header string false "ETag the person must still have"
+// @Success 200 {object} dto.PersonResponse
+// @Failure 400 {object} handler.ErrResponse
+// @Failure 404 {object} handler.ErrResponse
+// @Failure 409 {object} handler.ErrResponse
+// @Failure 412 {object} dto.PersonResponse "Current person"
+// @Failure 422 {object} handler.ValidationErrorResponse "The version breaks the current rules"
+// @Failure 500 {object} handler.ErrResponse
+// @Router /person/{id}/revert [post]
+func (h *PersonHandler) RevertPerson(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Error("failed to parse id", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid id"})
+		return
+	}
+
+	var req dto.RevertRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("Invalid revert request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid version"})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		h.preconditionFailed(c, id)
+		return
+	}
+
+	person, err := h.service.RevertPerson(c.Request.Context(), id, req.Version, version)
+	if err != nil {
+		var validationErr *service.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusUnprocessableEntity, newValidationErrorResponse(validationErr))
+		case errors.Is(err, repository.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrResponse{Error: "Person not found"})
+		case errors.Is(err, repository.ErrVersionNotFound):
+			c.JSON(http.StatusNotFound, ErrResponse{Error: "Version not found"})
+		case errors.Is(err, repository.ErrVersionConflict) && version != nil:
+			h.preconditionFailed(c, id)
+		case errors.Is(err, repository.ErrVersionConflict):
+			c.JSON(http.StatusConflict, ErrResponse{Error: "Person changed while it was reverted"})
+		default:
+			h.logger.Error("failed to revert person", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		}
+		return
+	}
+
+	h.logger.Info("Person reverted successfully", zap.String("id", idStr), zap.Int("version", req.Version))
+	c.Header(headerETag, personETag(person.Version))
+	c.JSON(http.StatusOK, dto.NewPersonResponse(person))
+}
+// GetTrash godoc
+// @Summary List deleted persons
+// @Description List the persons in the trash, most recently deleted first. Takes the filters of GET /persons
+// @Tags Trash
+// @Produce json
+// @Param page query int false "Page number (default: 1)" default(1)
+// @Param size query int false "Page size (default: 10)" default(10)
+// @Param name query string false "Name"
+// @Param surname query string false "Surname"
+// @Param sort query string false "Comma-separated sort fields, descending with a - prefix"
+// @Success 200 {array} dto.PersonResponse
+// @Failure 400 {object} handler.ErrResponse
+// @Failure 403 {object} handler.ErrResponse
+// @Failure 500 {object} handler.ErrResponse
+// @Security AdminToken
+// @Router /persons/trash [get]
+func (h *PersonHandler) GetTrash(c *gin.Context) {
+	var req dto.Filter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("Invalid trash request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid request body"})
+		return
+	}
+
+	persons, err := h.service.GetTrash(c.Request.Context(), &req)
+	if err != nil {
+		h.logger.Error("failed to get trash", zap.Error(err))
+		if errors.Is(err, service.ErrInvalidFilter) {
+			c.JSON(http.StatusBadRequest, ErrResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewPersonsResponse(*persons))
+}
+// PurgeTrash godoc
+// @Summary Purge the trash
+// @Description Permanently delete the persons that have been in the trash for longer than older_than, or the configured retention
+// @Tags Trash
+// @Produce json
+// @Param older_than query string false "Minimum time in the trash, such as 720h"
+// @Success 200 {object} dto.PurgeResponse
+// @Failure 400 {object} handler.ErrResponse
+// @Failure 403 {object} handler.ErrResponse
+// @Failure 500 {object} handler.ErrResponse
+// @Security AdminToken
+// @Router /admin/persons/purge [post]
+func (h *PersonHandler) PurgeTrash(c *gin.Context) {
+	var req dto.PurgeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("Invalid purge request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid request body"})
+		return
+	}
+
+	purged, err := h.service.PurgeDeleted(c.Request.Context(), req.OlderThan)
+	if err != nil {
+		h.logger.Error("failed to purge trash", zap.Error(err))
+		if errors.Is(err, service.ErrRetentionDisabled) {
+			c.JSON(http.StatusBadRequest, ErrResponse{Error: "Trash retention is not configured, pass older_than"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
 		return
 	}
 
-	c.JSON(http.StatusOK, filterPerson)
+	c.JSON(http.StatusOK, dto.PurgeResponse{Purged: purged})
 }
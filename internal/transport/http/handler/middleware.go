@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"Effective/internal/service"
+	"crypto/subtle"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	headerActor      = "X-Actor"
+	headerRequestID  = "X-Request-ID"
+	headerAdminToken = "X-Admin-Token"
+)
+
+// contextAdmin is the gin context key Admin sets for administrators.
+const contextAdmin = "admin"
+
+// maxRequestIDLength bounds a request id taken from the client.
+const maxRequestIDLength = 64
+
+// maxActorLength is the length of the actor columns, in characters.
+const maxActorLength = 255
+
+// Actor stores the caller named by the X-Actor header in the request context
+// so changes can be attributed to it. A name longer than the actor columns
+// is rejected rather than cut.
+func Actor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := c.GetHeader(headerActor)
+		if utf8.RuneCountInString(actor) > maxActorLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrResponse{Error: "X-Actor header is too long"})
+			return
+		}
+		if actor != "" {
+			c.Request = c.Request.WithContext(service.WithActor(c.Request.Context(), actor))
+		}
+		c.Next()
+	}
+}
+
+// Admin marks a request whose X-Admin-Token header matches token as made by
+// an administrator. With no token configured nobody is one.
+func Admin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given := c.GetHeader(headerAdminToken)
+		if token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1 {
+			c.Set(contextAdmin, true)
+		}
+		c.Next()
+	}
+}
+
+// RequireAdmin rejects requests not made by an administrator with 403.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isAdmin(c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, ErrResponse{Error: "Admin token required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func isAdmin(c *gin.Context) bool {
+	return c.GetBool(contextAdmin)
+}
+
+// RequestID stores the X-Request-ID header, or a new id when it is missing,
+// in the request context so changes can be traced to their request, and
+// echoes it in the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(headerRequestID)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+		c.Header(headerRequestID, requestID)
+		c.Request = c.Request.WithContext(service.WithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"Effective/internal/repository"
+	"Effective/internal/service"
+	"Effective/internal/transport/http/handler/dto"
+	"Effective/pkg/jsonpatch"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	mimeMergePatch = "application/merge-patch+json"
+	mimeJSONPatch  = "application/json-patch+json"
+)
+
+// patchPerson applies a JSON Merge Patch or JSON Patch body to the editable
+// fields of a person. The patch is applied to the version read here, so a
+// write in between fails instead of being overwritten.
+func (h *PersonHandler) patchPerson(c *gin.Context, id uuid.UUID, contentType string) {
+	body, err := c.GetRawData()
+	if err != nil {
+		h.logger.Error("failed to read patch", zap.Error(err))
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid request body"})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		h.preconditionFailed(c, id)
+		return
+	}
+
+	person, err := h.service.GetPerson(c.Request.Context(), id, false)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, ErrResponse{Error: "Person not found"})
+			return
+		}
+		h.logger.Error("failed to get person", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		return
+	}
+	if version != nil && *version != person.Version {
+		h.preconditionFailed(c, id)
+		return
+	}
+
+	current, err := json.Marshal(dto.NewPersonDocument(person))
+	if err != nil {
+		h.logger.Error("failed to encode person", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		return
+	}
+
+	var patched []byte
+	if contentType == mimeMergePatch {
+		patched, err = jsonpatch.MergePatch(current, body)
+	} else {
+		var patch jsonpatch.Patch
+		if patch, err = jsonpatch.Decode(body); err == nil {
+			patched, err = patch.Apply(current)
+		}
+	}
+	if err != nil {
+		h.logger.Error("failed to apply patch", zap.Error(err))
+		switch {
+		case errors.Is(err, jsonpatch.ErrTestFailed):
+			c.JSON(http.StatusConflict, ErrResponse{Error: err.Error()})
+		case errors.Is(err, jsonpatch.ErrPathNotFound):
+			c.JSON(http.StatusUnprocessableEntity, ErrResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, ErrResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	var doc dto.PersonDocument
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err == nil {
+		err = binding.Validator.ValidateStruct(&doc)
+	}
+	if err != nil {
+		h.logger.Error("Invalid patched person", zap.Error(err))
+		writeBindingError(c, err)
+		return
+	}
+
+	person, err = h.service.ReplacePerson(c.Request.Context(), id, &doc, &person.Version)
+	if err != nil {
+		h.logger.Error("failed to patch person", zap.Error(err))
+		var validationErr *service.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusUnprocessableEntity, newValidationErrorResponse(validationErr))
+		case errors.Is(err, repository.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrResponse{Error: "Person not found"})
+		case errors.Is(err, repository.ErrVersionConflict) && version != nil:
+			h.preconditionFailed(c, id)
+		case errors.Is(err, repository.ErrVersionConflict):
+			c.JSON(http.StatusConflict, ErrResponse{Error: "Person changed while it was patched"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrResponse{Error: "Internal server error"})
+		}
+		return
+	}
+
+	h.logger.Info("Person patched successfully", zap.String("id", id.String()))
+	c.Header(headerETag, personETag(person.Version))
+	c.JSON(http.StatusOK, true)
+}
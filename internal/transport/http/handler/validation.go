@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"Effective/internal/service"
+	"Effective/pkg/countries"
+	"Effective/pkg/names"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	tagPersonName = "personname"
+	tagCountry    = "country"
+)
+
+// Codes of binding failures that have no counterpart in the service rules.
+const (
+	codeInvalidType  = "invalid_type"
+	codeInvalidValue = "invalid_value"
+	codeNotAllowed   = "not_allowed"
+)
+
+// RegisterValidators adds the custom binding tags used by the request DTOs to
+// gin's validator. personname accepts a name in any script as checked by
+// names.Valid, and country an ISO 3166-1 alpha-2 code in either case as
+// checked by countries.Valid. Fields are reported by their JSON or form name.
+func RegisterValidators() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("unexpected binding validator engine")
+	}
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" && name != "-" {
+				return name
+			}
+		}
+		return field.Name
+	})
+
+	if err := v.RegisterValidation(tagPersonName, func(fl validator.FieldLevel) bool {
+		return names.Valid(fl.Field().String())
+	}); err != nil {
+		return err
+	}
+	return v.RegisterValidation(tagCountry, func(fl validator.FieldLevel) bool {
+		return countries.Valid(strings.ToUpper(fl.Field().String()))
+	})
+}
+
+// writeBindingError answers a request that failed to bind with 422 and the
+// fields that broke a rule, the status of every validation failure, or with
+// 400 when the body could not be read field by field.
+func writeBindingError(c *gin.Context, err error) {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]service.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, bindingFieldError(fe))
+		}
+		c.JSON(http.StatusUnprocessableEntity, newValidationErrorResponse(&service.ValidationError{Fields: fields}))
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		c.JSON(http.StatusUnprocessableEntity, newValidationErrorResponse(&service.ValidationError{Fields: []service.FieldError{{
+			Field:   typeErr.Field,
+			Code:    codeInvalidType,
+			Message: fmt.Sprintf("%s must be of type %s", typeErr.Field, typeErr.Type),
+		}}}))
+	default:
+		c.JSON(http.StatusBadRequest, ErrResponse{Error: "Invalid request body"})
+	}
+}
+
+// bindingFieldError translates a failed binding tag to the code the service
+// uses for the same rule.
+func bindingFieldError(fe validator.FieldError) service.FieldError {
+	field := fe.Field()
+	numeric := fe.Kind() != reflect.String && fe.Kind() != reflect.Slice
+	switch fe.Tag() {
+	case "required", "required_without":
+		return service.FieldError{Field: field, Code: service.CodeRequired, Message: field + " is required"}
+	case "min":
+		if numeric {
+			return service.FieldError{Field: field, Code: service.CodeOutOfRange, Message: fmt.Sprintf("%s must be at least %s", field, fe.Param())}
+		}
+		return service.FieldError{Field: field, Code: service.CodeTooShort, Message: fmt.Sprintf("%s must be at least %s characters", field, fe.Param())}
+	case "max":
+		if numeric {
+			return service.FieldError{Field: field, Code: service.CodeOutOfRange, Message: fmt.Sprintf("%s must be at most %s", field, fe.Param())}
+		}
+		return service.FieldError{Field: field, Code: service.CodeTooLong, Message: fmt.Sprintf("%s must be at most %s characters", field, fe.Param())}
+	case tagPersonName:
+		return service.FieldError{Field: field, Code: service.CodeInvalidName, Message: field + " must be letters joined by spaces, hyphens or apostrophes"}
+	case tagCountry:
+		return service.FieldError{Field: field, Code: service.CodeUnknownCountry, Message: field + " must be an ISO 3166-1 alpha-2 country code"}
+	case "oneof":
+		return service.FieldError{Field: field, Code: codeNotAllowed, Message: fmt.Sprintf("%s must be one of %s", field, fe.Param())}
+	case "excluded_with":
+		return service.FieldError{Field: field, Code: codeNotAllowed, Message: fmt.Sprintf("%s cannot be combined with %s", field, fe.Param())}
+	default:
+		return service.FieldError{Field: field, Code: codeInvalidValue, Message: fmt.Sprintf("%s fails the %s rule", field, fe.Tag())}
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"Effective/internal/service"
+	"Effective/internal/transport/http/handler/dto"
+	"errors"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func TestCountryBinding(t *testing.T) {
+	if err := RegisterValidators(); err != nil {
+		t.Fatalf("RegisterValidators() error = %v", err)
+	}
+
+	tests := []struct {
+		countryID string
+		wantErr   bool
+	}{
+		{"", false},
+		{"RU", false},
+		{"ru", false},
+		{"XK", false},
+		{"ZZ", true},
+		{"R1", true},
+		{"RUS", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.countryID, func(t *testing.T) {
+			req := dto.CreatePersonRequest{Name: "Anna", Surname: "Petrova", CountryID: tt.countryID}
+			err := binding.Validator.ValidateStruct(&req)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("ValidateStruct() error = %v", err)
+				}
+				return
+			}
+
+			var validationErrs validator.ValidationErrors
+			if !errors.As(err, &validationErrs) || len(validationErrs) != 1 {
+				t.Fatalf("ValidateStruct() error = %v, want one field error", err)
+			}
+			got := bindingFieldError(validationErrs[0])
+			if got.Field != "country_id" || got.Code != service.CodeUnknownCountry {
+				t.Errorf("bindingFieldError() = %+v, want country_id with %s", got, service.CodeUnknownCountry)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS name_enrichment_cache (
+     name VARCHAR(255) NOT NULL,
+     attribute VARCHAR(32) NOT NULL,
+     value JSONB NOT NULL,
+     expires_at TIMESTAMPTZ NOT NULL,
+     created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+     PRIMARY KEY (name, attribute)
+);
+
+CREATE INDEX IF NOT EXISTS idx_name_enrichment_cache_expires_at ON name_enrichment_cache (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS name_enrichment_cache;
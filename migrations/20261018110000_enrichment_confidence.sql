@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE persons
+     ADD COLUMN IF NOT EXISTS age_count INTEGER NOT NULL DEFAULT 0,
+     ADD COLUMN IF NOT EXISTS gender_probability DOUBLE PRECISION NOT NULL DEFAULT 0,
+     ADD COLUMN IF NOT EXISTS gender_count INTEGER NOT NULL DEFAULT 0,
+     ADD COLUMN IF NOT EXISTS nationality_probability DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- Cached values were bare ages, genders and country codes without confidence.
+DELETE FROM name_enrichment_cache;
+
+-- +goose Down
+ALTER TABLE persons
+     DROP COLUMN IF EXISTS age_count,
+     DROP COLUMN IF EXISTS gender_probability,
+     DROP COLUMN IF EXISTS gender_count,
+     DROP COLUMN IF EXISTS nationality_probability;
+
+DELETE FROM name_enrichment_cache;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS person_nationality_candidates (
+     person_id UUID NOT NULL REFERENCES persons (id) ON DELETE CASCADE,
+     country_id VARCHAR(8) NOT NULL,
+     probability DOUBLE PRECISION NOT NULL,
+     PRIMARY KEY (person_id, country_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_person_nationality_candidates_country
+     ON person_nationality_candidates (country_id, probability);
+
+-- Cached nationality predictions only held the top country.
+DELETE FROM name_enrichment_cache WHERE attribute = 'nationality';
+
+-- +goose Down
+DROP TABLE IF EXISTS person_nationality_candidates;
@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE name_enrichment_cache ADD COLUMN IF NOT EXISTS country_id VARCHAR(8) NOT NULL DEFAULT '';
+ALTER TABLE name_enrichment_cache DROP CONSTRAINT IF EXISTS name_enrichment_cache_pkey;
+ALTER TABLE name_enrichment_cache ADD PRIMARY KEY (name, attribute, country_id);
+
+-- +goose Down
+DELETE FROM name_enrichment_cache WHERE country_id <> '';
+ALTER TABLE name_enrichment_cache DROP CONSTRAINT IF EXISTS name_enrichment_cache_pkey;
+ALTER TABLE name_enrichment_cache ADD PRIMARY KEY (name, attribute);
+ALTER TABLE name_enrichment_cache DROP COLUMN IF EXISTS country_id;
@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE persons
+     ADD COLUMN IF NOT EXISTS age_source VARCHAR(64) NOT NULL DEFAULT '',
+     ADD COLUMN IF NOT EXISTS gender_source VARCHAR(64) NOT NULL DEFAULT '',
+     ADD COLUMN IF NOT EXISTS nationality_source VARCHAR(64) NOT NULL DEFAULT '';
+
+-- Cached predictions do not know which provider produced them.
+DELETE FROM name_enrichment_cache;
+
+-- +goose Down
+ALTER TABLE persons
+     DROP COLUMN IF EXISTS age_source,
+     DROP COLUMN IF EXISTS gender_source,
+     DROP COLUMN IF EXISTS nationality_source;
@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE persons ADD COLUMN IF NOT EXISTS enrichment_status VARCHAR(16) NOT NULL DEFAULT 'completed';
+
+CREATE TABLE IF NOT EXISTS enrichment_jobs (
+     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+     person_id UUID NOT NULL REFERENCES persons (id) ON DELETE CASCADE,
+     country_id VARCHAR(8) NOT NULL DEFAULT '',
+     status VARCHAR(16) NOT NULL DEFAULT 'pending',
+     attempts INTEGER NOT NULL DEFAULT 0,
+     max_attempts INTEGER NOT NULL,
+     last_error TEXT NOT NULL DEFAULT '',
+     run_after TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+     locked_at TIMESTAMPTZ,
+     created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+     updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_claim ON enrichment_jobs (status, run_after);
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_person ON enrichment_jobs (person_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS enrichment_jobs;
+ALTER TABLE persons DROP COLUMN IF EXISTS enrichment_status;
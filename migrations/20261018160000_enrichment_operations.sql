@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE persons ADD COLUMN IF NOT EXISTS enriched_at TIMESTAMPTZ;
+UPDATE persons SET enriched_at = updated_at WHERE enrichment_status <> 'pending';
+
+CREATE TABLE IF NOT EXISTS enrichment_operations (
+     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+     total INTEGER NOT NULL DEFAULT 0,
+     created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE enrichment_jobs ADD COLUMN IF NOT EXISTS operation_id UUID REFERENCES enrichment_operations (id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_operation ON enrichment_jobs (operation_id);
+
+-- +goose Down
+ALTER TABLE enrichment_jobs DROP COLUMN IF EXISTS operation_id;
+DROP TABLE IF EXISTS enrichment_operations;
+ALTER TABLE persons DROP COLUMN IF EXISTS enriched_at;
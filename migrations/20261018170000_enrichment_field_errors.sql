@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE persons ADD COLUMN IF NOT EXISTS enrichment_errors JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE enrichment_jobs ADD COLUMN IF NOT EXISTS fields TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE enrichment_jobs DROP COLUMN IF EXISTS fields;
+ALTER TABLE persons DROP COLUMN IF EXISTS enrichment_errors;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS person_field_provenance (
+     person_id UUID NOT NULL REFERENCES persons (id) ON DELETE CASCADE,
+     field VARCHAR(32) NOT NULL,
+     source VARCHAR(64) NOT NULL,
+     actor VARCHAR(255) NOT NULL DEFAULT '',
+     locked BOOLEAN NOT NULL DEFAULT FALSE,
+     updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+     PRIMARY KEY (person_id, field)
+);
+
+INSERT INTO person_field_provenance (person_id, field, source, actor, updated_at)
+SELECT id, 'age', age_source, 'system', updated_at FROM persons WHERE age_source <> ''
+UNION ALL
+SELECT id, 'gender', gender_source, 'system', updated_at FROM persons WHERE gender_source <> ''
+UNION ALL
+SELECT id, 'nationality', nationality_source, 'system', updated_at FROM persons WHERE nationality_source <> ''
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE enrichment_jobs ADD COLUMN IF NOT EXISTS force BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE enrichment_jobs DROP COLUMN IF EXISTS force;
+DROP TABLE IF EXISTS person_field_provenance;
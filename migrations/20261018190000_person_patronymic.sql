@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE persons ADD COLUMN IF NOT EXISTS patronymic VARCHAR(100) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE persons DROP COLUMN IF EXISTS patronymic;
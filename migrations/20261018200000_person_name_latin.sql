@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE persons
+    ADD COLUMN IF NOT EXISTS name_latin VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS surname_latin VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS patronymic_latin VARCHAR(100) NOT NULL DEFAULT '';
+
+-- Names already spelled in ASCII Latin are their own transliteration.
+UPDATE persons SET name_latin = name WHERE name ~ '^[A-Za-z'' -]*$';
+UPDATE persons SET surname_latin = surname WHERE surname ~ '^[A-Za-z'' -]*$';
+UPDATE persons SET patronymic_latin = patronymic WHERE patronymic ~ '^[A-Za-z'' -]*$';
+
+-- +goose Down
+ALTER TABLE persons
+    DROP COLUMN IF EXISTS name_latin,
+    DROP COLUMN IF EXISTS surname_latin,
+    DROP COLUMN IF EXISTS patronymic_latin;
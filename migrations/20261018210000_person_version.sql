@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE persons ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE persons DROP COLUMN IF EXISTS version;
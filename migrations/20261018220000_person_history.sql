@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS person_history (
+     id BIGSERIAL PRIMARY KEY,
+     person_id UUID NOT NULL REFERENCES persons (id) ON DELETE CASCADE,
+     version INTEGER NOT NULL,
+     action VARCHAR(16) NOT NULL,
+     snapshot_before JSONB,
+     snapshot_after JSONB NOT NULL,
+     actor VARCHAR(255) NOT NULL DEFAULT '',
+     request_id VARCHAR(64) NOT NULL DEFAULT '',
+     created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_person_history_person ON person_history (person_id, version);
+
+-- +goose Down
+DROP TABLE IF EXISTS person_history;
@@ -0,0 +1,111 @@
+-- +goose Up
+-- person_versions keeps every row persons ever held, valid from valid_from
+-- until valid_to, or still current while valid_to is NULL. It mirrors the
+-- columns of persons, so a column added there must be added here and to the
+-- column lists of version_persons() too. Rows of purged persons stay for
+-- point-in-time reads.
+CREATE TABLE IF NOT EXISTS person_versions (LIKE persons);
+ALTER TABLE person_versions
+     ADD COLUMN valid_from TIMESTAMPTZ NOT NULL,
+     ADD COLUMN valid_to TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_person_versions_id ON person_versions (id, valid_from);
+CREATE INDEX IF NOT EXISTS idx_person_versions_validity ON person_versions (valid_from, valid_to);
+
+CREATE TABLE IF NOT EXISTS person_nationality_candidate_versions (
+     person_id UUID NOT NULL,
+     country_id VARCHAR(8) NOT NULL,
+     probability DOUBLE PRECISION NOT NULL,
+     valid_from TIMESTAMPTZ NOT NULL,
+     valid_to TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_person_nationality_candidate_versions_person
+     ON person_nationality_candidate_versions (person_id, valid_from);
+CREATE INDEX IF NOT EXISTS idx_person_nationality_candidate_versions_country
+     ON person_nationality_candidate_versions (country_id, valid_from);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION version_persons() RETURNS TRIGGER AS $$
+BEGIN
+     IF TG_OP IN ('UPDATE', 'DELETE') THEN
+          UPDATE person_versions SET valid_to = NOW()
+          WHERE id = OLD.id AND valid_to IS NULL;
+     END IF;
+     IF TG_OP IN ('INSERT', 'UPDATE') THEN
+          INSERT INTO person_versions (
+               id, name, surname, patronymic, name_latin, surname_latin, patronymic_latin,
+               age, age_count, age_source, gender, gender_probability, gender_count, gender_source,
+               nationality, nationality_probability, nationality_source,
+               enrichment_status, enrichment_errors, enriched_at,
+               version, created_at, updated_at, deleted_at,
+               valid_from
+          ) VALUES (
+               NEW.id, NEW.name, NEW.surname, NEW.patronymic, NEW.name_latin, NEW.surname_latin, NEW.patronymic_latin,
+               NEW.age, NEW.age_count, NEW.age_source, NEW.gender, NEW.gender_probability, NEW.gender_count, NEW.gender_source,
+               NEW.nationality, NEW.nationality_probability, NEW.nationality_source,
+               NEW.enrichment_status, NEW.enrichment_errors, NEW.enriched_at,
+               NEW.version, NEW.created_at, NEW.updated_at, NEW.deleted_at,
+               NOW()
+          );
+     END IF;
+     RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION version_person_nationality_candidates() RETURNS TRIGGER AS $$
+BEGIN
+     IF TG_OP IN ('UPDATE', 'DELETE') THEN
+          UPDATE person_nationality_candidate_versions SET valid_to = NOW()
+          WHERE person_id = OLD.person_id AND country_id = OLD.country_id AND valid_to IS NULL;
+     END IF;
+     IF TG_OP IN ('INSERT', 'UPDATE') THEN
+          INSERT INTO person_nationality_candidate_versions (person_id, country_id, probability, valid_from)
+          VALUES (NEW.person_id, NEW.country_id, NEW.probability, NOW());
+     END IF;
+     RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER persons_versioning
+     AFTER INSERT OR UPDATE OR DELETE ON persons
+     FOR EACH ROW EXECUTE FUNCTION version_persons();
+
+CREATE TRIGGER person_nationality_candidates_versioning
+     AFTER INSERT OR UPDATE OR DELETE ON person_nationality_candidates
+     FOR EACH ROW EXECUTE FUNCTION version_person_nationality_candidates();
+
+-- Earlier states were not kept: existing rows count as valid since their
+-- person was created.
+INSERT INTO person_versions (
+     id, name, surname, patronymic, name_latin, surname_latin, patronymic_latin,
+     age, age_count, age_source, gender, gender_probability, gender_count, gender_source,
+     nationality, nationality_probability, nationality_source,
+     enrichment_status, enrichment_errors, enriched_at,
+     version, created_at, updated_at, deleted_at,
+     valid_from
+)
+SELECT
+     id, name, surname, patronymic, name_latin, surname_latin, patronymic_latin,
+     age, age_count, age_source, gender, gender_probability, gender_count, gender_source,
+     nationality, nationality_probability, nationality_source,
+     enrichment_status, enrichment_errors, enriched_at,
+     version, created_at, updated_at, deleted_at,
+     created_at
+FROM persons;
+
+INSERT INTO person_nationality_candidate_versions (person_id, country_id, probability, valid_from)
+SELECT c.person_id, c.country_id, c.probability, p.created_at
+FROM person_nationality_candidates c
+JOIN persons p ON p.id = c.person_id;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS person_nationality_candidates_versioning ON person_nationality_candidates;
+DROP TRIGGER IF EXISTS persons_versioning ON persons;
+DROP FUNCTION IF EXISTS version_person_nationality_candidates();
+DROP FUNCTION IF EXISTS version_persons();
+DROP TABLE IF EXISTS person_nationality_candidate_versions;
+DROP TABLE IF EXISTS person_versions;
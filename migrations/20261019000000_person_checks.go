@@ -0,0 +1,221 @@
+// Package migrations holds the schema migrations written in Go. They register
+// with goose on import and run with the SQL files of this directory.
+package migrations
+
+import (
+	"Effective/internal/domain"
+	"Effective/pkg/countries"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pressly/goose/v3"
+)
+
+// The limits the service enforced when the person checks were added.
+const (
+	checksMaxNameLength = 50
+	checksMaxAge        = 150
+)
+
+func init() {
+	goose.AddMigrationContext(upPersonChecks, downPersonChecks)
+}
+
+// upPersonChecks brings stored values in line with the rules the service
+// enforces, then adds CHECK constraints for them. Names are cut to 50
+// characters, gender is put in lower case and nationality in upper case, and
+// values no rule accepts are cleared so enrichment can fill them in again.
+// Every person changed moves to a new version recorded in its history, so it
+// can be reverted like any other change. The nationality check takes its
+// codes from pkg/countries.
+func upPersonChecks(ctx context.Context, tx *sql.Tx) error {
+	persons, err := readPersons(ctx, tx)
+	if err != nil {
+		return err
+	}
+	for i := range persons {
+		before := persons[i]
+		if !cleanPerson(&persons[i]) {
+			continue
+		}
+		if err := savePerson(ctx, tx, &before, &persons[i]); err != nil {
+			return err
+		}
+	}
+
+	quoted := make([]string, 0, len(countries.Codes()))
+	for _, code := range countries.Codes() {
+		quoted = append(quoted, "'"+code+"'")
+	}
+
+	// A name shorter than two characters cannot be repaired here. The name
+	// checks are added NOT VALID so such rows do not stop the migration, and
+	// are validated once no such row is left; until then they only apply to
+	// writes.
+	query := fmt.Sprintf(`
+		ALTER TABLE persons
+			ADD CONSTRAINT persons_name_check CHECK (char_length(name) BETWEEN 2 AND %[1]d) NOT VALID,
+			ADD CONSTRAINT persons_surname_check CHECK (char_length(surname) BETWEEN 2 AND %[1]d) NOT VALID,
+			ADD CONSTRAINT persons_patronymic_check CHECK (patronymic = '' OR char_length(patronymic) BETWEEN 2 AND %[1]d) NOT VALID,
+			ADD CONSTRAINT persons_age_check CHECK (age BETWEEN 0 AND %[2]d),
+			ADD CONSTRAINT persons_gender_check CHECK (gender IN ('', 'male', 'female')),
+			ADD CONSTRAINT persons_gender_probability_check CHECK (gender_probability BETWEEN 0 AND 1),
+			ADD CONSTRAINT persons_nationality_probability_check CHECK (nationality_probability BETWEEN 0 AND 1),
+			ADD CONSTRAINT persons_counts_check CHECK (age_count >= 0 AND gender_count >= 0),
+			ADD CONSTRAINT persons_nationality_check CHECK (nationality IN ('', 'unknown', %[3]s))`,
+		checksMaxNameLength, checksMaxAge, strings.Join(quoted, ", "))
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to add person checks: %w", err)
+	}
+
+	for _, check := range []struct {
+		constraint string
+		invalid    string
+	}{
+		{"persons_name_check", "char_length(name) < 2"},
+		{"persons_surname_check", "char_length(surname) < 2"},
+		{"persons_patronymic_check", "char_length(patronymic) = 1"},
+	} {
+		var invalid bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM persons WHERE `+check.invalid+`)`).Scan(&invalid); err != nil {
+			return fmt.Errorf("failed to look for short names: %w", err)
+		}
+		if invalid {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `ALTER TABLE persons VALIDATE CONSTRAINT `+check.constraint); err != nil {
+			return fmt.Errorf("failed to validate %s: %w", check.constraint, err)
+		}
+	}
+	return nil
+}
+
+func downPersonChecks(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		ALTER TABLE persons
+			DROP CONSTRAINT IF EXISTS persons_nationality_check,
+			DROP CONSTRAINT IF EXISTS persons_counts_check,
+			DROP CONSTRAINT IF EXISTS persons_nationality_probability_check,
+			DROP CONSTRAINT IF EXISTS persons_gender_probability_check,
+			DROP CONSTRAINT IF EXISTS persons_gender_check,
+			DROP CONSTRAINT IF EXISTS persons_age_check,
+			DROP CONSTRAINT IF EXISTS persons_patronymic_check,
+			DROP CONSTRAINT IF EXISTS persons_surname_check,
+			DROP CONSTRAINT IF EXISTS persons_name_check`
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to drop person checks: %w", err)
+	}
+	return nil
+}
+
+// readPersons reads the fields of every person that a snapshot holds.
+func readPersons(ctx context.Context, tx *sql.Tx) ([]domain.Person, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, name, surname, patronymic,
+			age, age_count, age_source, gender, gender_probability, gender_count, gender_source,
+			nationality, nationality_probability, nationality_source,
+			enrichment_status, version, deleted_at
+		FROM persons
+		FOR UPDATE`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read persons: %w", err)
+	}
+	defer rows.Close()
+
+	var persons []domain.Person
+	for rows.Next() {
+		var p domain.Person
+		if err := rows.Scan(
+			&p.ID, &p.Name, &p.Surname, &p.Patronymic,
+			&p.Age, &p.AgeCount, &p.AgeSource, &p.Gender, &p.GenderProbability, &p.GenderCount, &p.GenderSource,
+			&p.Nationality, &p.NationalityProbability, &p.NationalitySource,
+			&p.EnrichmentStatus, &p.Version, &p.DeletedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan person: %w", err)
+		}
+		persons = append(persons, p)
+	}
+	return persons, rows.Err()
+}
+
+// cleanPerson repairs the values of p that the checks would reject and
+// reports whether it changed any.
+func cleanPerson(p *domain.Person) bool {
+	before := *p
+
+	for _, name := range []*string{&p.Name, &p.Surname, &p.Patronymic} {
+		if utf8.RuneCountInString(*name) > checksMaxNameLength {
+			*name = string([]rune(*name)[:checksMaxNameLength])
+		}
+	}
+
+	gender := strings.ToLower(strings.TrimSpace(p.Gender))
+	if slices.Contains([]string{"", "male", "female"}, gender) {
+		p.Gender = gender
+	} else {
+		p.Gender, p.GenderProbability, p.GenderCount, p.GenderSource = "", 0, 0, ""
+	}
+
+	if p.Age < 0 || p.Age > checksMaxAge {
+		p.Age, p.AgeCount, p.AgeSource = 0, 0, ""
+	}
+
+	nationality := strings.TrimSpace(p.Nationality)
+	switch {
+	case strings.EqualFold(nationality, "unknown"):
+		p.Nationality = "unknown"
+	case nationality == "" || countries.Valid(strings.ToUpper(nationality)):
+		p.Nationality = strings.ToUpper(nationality)
+	default:
+		p.Nationality, p.NationalityProbability, p.NationalitySource = "", 0, ""
+	}
+
+	return *domain.NewPersonSnapshot(p) != *domain.NewPersonSnapshot(&before)
+}
+
+// savePerson writes the cleaned person as a new version and records the
+// change in its history as made by the system.
+func savePerson(ctx context.Context, tx *sql.Tx, before, after *domain.Person) error {
+	err := tx.QueryRowContext(ctx, `
+		UPDATE persons
+		SET name = $1, surname = $2, patronymic = $3,
+			age = $4, age_count = $5, age_source = $6,
+			gender = $7, gender_probability = $8, gender_count = $9, gender_source = $10,
+			nationality = $11, nationality_probability = $12, nationality_source = $13,
+			version = version + 1, updated_at = NOW()
+		WHERE id = $14
+		RETURNING version`,
+		after.Name, after.Surname, after.Patronymic,
+		after.Age, after.AgeCount, after.AgeSource,
+		after.Gender, after.GenderProbability, after.GenderCount, after.GenderSource,
+		after.Nationality, after.NationalityProbability, after.NationalitySource,
+		after.ID,
+	).Scan(&after.Version)
+	if err != nil {
+		return fmt.Errorf("failed to clean person %s: %w", after.ID, err)
+	}
+
+	snapshotBefore, err := json.Marshal(domain.NewPersonSnapshot(before))
+	if err != nil {
+		return fmt.Errorf("failed to encode person snapshot: %w", err)
+	}
+	snapshotAfter, err := json.Marshal(domain.NewPersonSnapshot(after))
+	if err != nil {
+		return fmt.Errorf("failed to encode person snapshot: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO person_history (person_id, version, action, snapshot_before, snapshot_after, actor)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		after.ID, after.Version, domain.ActionUpdate, snapshotBefore, snapshotAfter, domain.ActorSystem,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record person change: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package migrations
+
+import (
+	"Effective/internal/domain"
+	"strings"
+	"testing"
+)
+
+func TestCleanPerson(t *testing.T) {
+	valid := domain.Person{
+		Name:                   "Anna",
+		Surname:                "Petrova",
+		Age:                    30,
+		AgeSource:              "agify",
+		Gender:                 "female",
+		GenderProbability:      0.98,
+		GenderSource:           "genderize",
+		Nationality:            "RU",
+		NationalityProbability: 0.7,
+		NationalitySource:      "nationalize",
+	}
+
+	tests := []struct {
+		name        string
+		change      func(p *domain.Person)
+		want        func(p *domain.Person)
+		wantChanged bool
+	}{
+		{
+			name:   "valid person is kept",
+			change: func(p *domain.Person) {},
+			want:   func(p *domain.Person) {},
+		},
+		{
+			name:        "long name is cut by characters",
+			change:      func(p *domain.Person) { p.Name = strings.Repeat("Я", 60) },
+			want:        func(p *domain.Person) { p.Name = strings.Repeat("Я", 50) },
+			wantChanged: true,
+		},
+		{
+			name:        "gender is put in lower case",
+			change:      func(p *domain.Person) { p.Gender = " Female" },
+			want:        func(p *domain.Person) {},
+			wantChanged: true,
+		},
+		{
+			name:   "unknown gender is cleared",
+			change: func(p *domain.Person) { p.Gender = "other" },
+			want: func(p *domain.Person) {
+				p.Gender, p.GenderProbability, p.GenderSource = "", 0, ""
+			},
+			wantChanged: true,
+		},
+		{
+			name:        "age out of range is cleared",
+			change:      func(p *domain.Person) { p.Age = 200 },
+			want:        func(p *domain.Person) { p.Age, p.AgeSource = 0, "" },
+			wantChanged: true,
+		},
+		{
+			name:        "nationality is put in upper case",
+			change:      func(p *domain.Person) { p.Nationality = "ru" },
+			want:        func(p *domain.Person) {},
+			wantChanged: true,
+		},
+		{
+			name:        "unknown keeps its spelling",
+			change:      func(p *domain.Person) { p.Nationality = "UNKNOWN" },
+			want:        func(p *domain.Person) { p.Nationality = "unknown" },
+			wantChanged: true,
+		},
+		{
+			name:   "unassigned country is cleared",
+			change: func(p *domain.Person) { p.Nationality = "ZZ" },
+			want: func(p *domain.Person) {
+				p.Nationality, p.NationalityProbability, p.NationalitySource = "", 0, ""
+			},
+			wantChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, want := valid, valid
+			tt.change(&got)
+			tt.want(&want)
+
+			if changed := cleanPerson(&got); changed != tt.wantChanged {
+				t.Errorf("cleanPerson() = %v, want %v", changed, tt.wantChanged)
+			}
+			if *domain.NewPersonSnapshot(&got) != *domain.NewPersonSnapshot(&want) {
+				t.Errorf("cleaned person = %+v, want %+v", domain.NewPersonSnapshot(&got), domain.NewPersonSnapshot(&want))
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+// Package cassette provides an http.RoundTripper that records responses to
+// files and replays them later, so integration tests and demos can run
+// against captured upstream answers.
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	ModePassthrough = "passthrough"
+	ModeRecord      = "record"
+	ModeReplay      = "replay"
+)
+
+// ErrNoRecording is returned in replay mode for a request without a
+// recorded response.
+var ErrNoRecording = errors.New("no recorded response")
+
+// Cassette is one recorded request and its response.
+type Cassette struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// Transport records or replays responses according to its mode. Cassettes
+// are stored in dir, one file per method, URL and query; the query is keyed
+// in sorted order so parameter order does not matter.
+type Transport struct {
+	mode string
+	dir  string
+	next http.RoundTripper
+
+	mu sync.Mutex
+}
+
+// NewTransport returns a Transport in mode that stores cassettes in dir. next
+// sends the requests that are passed through or recorded and defaults to
+// http.DefaultTransport.
+func NewTransport(mode, dir string, next http.RoundTripper) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{mode: mode, dir: dir, next: next}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch t.mode {
+	case ModeRecord:
+		return t.record(req)
+	case ModeReplay:
+		return t.replay(req)
+	default:
+		return t.next.RoundTrip(req)
+	}
+}
+
+func (t *Transport) record(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	cassette := Cassette{
+		Request: Request{Method: req.Method, URL: Key(req)},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       string(body),
+		},
+	}
+	if err := t.save(t.path(req), &cassette); err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func (t *Transport) replay(req *http.Request) (*http.Response, error) {
+	path := t.path(req)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w for %s %s (cassette %s)", ErrNoRecording, req.Method, Key(req), path)
+		}
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("failed to decode cassette %s: %w", path, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", cassette.Response.StatusCode, http.StatusText(cassette.Response.StatusCode)),
+		StatusCode:    cassette.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cassette.Response.Header,
+		Body:          io.NopCloser(strings.NewReader(cassette.Response.Body)),
+		ContentLength: int64(len(cassette.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *Transport) save(path string, cassette *Cassette) error {
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// path names the cassette of req after its host and a hash of its key.
+func (t *Transport) path(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Method + " " + Key(req)))
+	host := strings.NewReplacer(":", "_", "/", "_").Replace(req.URL.Host)
+	return filepath.Join(t.dir, fmt.Sprintf("%s_%s_%s.json", req.Method, host, hex.EncodeToString(sum[:8])))
+}
+
+// Key returns the URL of req with its query parameters in sorted order.
+func Key(req *http.Request) string {
+	u := *req.URL
+	u.RawQuery = u.Query().Encode()
+	u.Fragment = ""
+	return u.String()
+}
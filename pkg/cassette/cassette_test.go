@@ -0,0 +1,151 @@
+package cassette
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+
+	var hits atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		w.Header().Set("X-Hit", fmt.Sprint(n))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "%s %s", r.Method, r.URL.RawQuery)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func do(t *testing.T, transport http.RoundTripper, method, url string) (*http.Response, string, error) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body), nil
+}
+
+func TestRecordReplay(t *testing.T) {
+	srv, hits := newTestServer(t)
+	dir := t.TempDir()
+
+	recorded, recordedBody, err := do(t, NewTransport(ModeRecord, dir, nil), http.MethodGet, srv.URL+"/age?name=anna&country_id=RU")
+	if err != nil {
+		t.Fatalf("recording: %v", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("got %d cassettes, want 1", len(files))
+	}
+
+	// The query is keyed in sorted order, so the reordered URL replays.
+	replayed, replayedBody, err := do(t, NewTransport(ModeReplay, dir, nil), http.MethodGet, srv.URL+"/age?country_id=RU&name=anna")
+	if err != nil {
+		t.Fatalf("replaying: %v", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("server got %d requests, want 1", hits.Load())
+	}
+	if replayed.StatusCode != recorded.StatusCode || replayedBody != recordedBody {
+		t.Errorf("replayed %d %q, recorded %d %q", replayed.StatusCode, replayedBody, recorded.StatusCode, recordedBody)
+	}
+	if got := replayed.Header.Get("X-Hit"); got != "1" {
+		t.Errorf("replayed X-Hit = %q, want 1", got)
+	}
+}
+
+func TestReplayUnrecorded(t *testing.T) {
+	srv, hits := newTestServer(t)
+	dir := t.TempDir()
+
+	if _, _, err := do(t, NewTransport(ModeRecord, dir, nil), http.MethodGet, srv.URL+"/age?name=anna"); err != nil {
+		t.Fatalf("recording: %v", err)
+	}
+
+	replay := NewTransport(ModeReplay, dir, nil)
+	tests := []struct {
+		name   string
+		method string
+		url    string
+	}{
+		{"other query", http.MethodGet, srv.URL + "/age?name=olga"},
+		{"other method", http.MethodPost, srv.URL + "/age?name=anna"},
+		{"other path", http.MethodGet, srv.URL + "/gender?name=anna"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := do(t, replay, tt.method, tt.url); !errors.Is(err, ErrNoRecording) {
+				t.Errorf("error = %v, want %v", err, ErrNoRecording)
+			}
+		})
+	}
+	if hits.Load() != 1 {
+		t.Errorf("server got %d requests, want 1", hits.Load())
+	}
+}
+
+func TestPassthrough(t *testing.T) {
+	srv, hits := newTestServer(t)
+	dir := t.TempDir()
+	transport := NewTransport(ModePassthrough, dir, nil)
+
+	for i := 1; i <= 2; i++ {
+		resp, _, err := do(t, transport, http.MethodGet, srv.URL+"/age?name=anna")
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		if got := resp.Header.Get("X-Hit"); got != fmt.Sprint(i) {
+			t.Errorf("request %d: X-Hit = %q, want %d", i, got, i)
+		}
+	}
+	if hits.Load() != 2 {
+		t.Errorf("server got %d requests, want 2", hits.Load())
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("passthrough wrote %d cassettes, want none", len(files))
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"sorts query", "https://api.test/age?name=anna&country_id=RU", "https://api.test/age?country_id=RU&name=anna"},
+		{"keeps repeated parameters in order", "https://api.test/age?name[]=b&name[]=a", "https://api.test/age?name%5B%5D=b&name%5B%5D=a"},
+		{"drops fragment", "https://api.test/age?name=anna#top", "https://api.test/age?name=anna"},
+		{"no query", "https://api.test/age", "https://api.test/age"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Key(req); got != tt.want {
+				t.Errorf("Key() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+// Package fakeenrich serves deterministic imitations of the agify, genderize
+// and nationalize APIs for local development and tests. Every answer is
+// derived from a hash of the name, so the same name always gets the same
+// prediction, and faults such as latency, 429s, 5xx errors and malformed
+// JSON can be injected on demand.
+//
+// A Server is an http.Handler and works with httptest:
+//
+//	srv := httptest.NewServer(fakeenrich.New(fakeenrich.Config{}))
+//	defer srv.Close()
+//	urls := fakeenrich.URLsFor(srv.URL)
+package fakeenrich
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	APIAgify       = "agify"
+	APIGenderize   = "genderize"
+	APINationalize = "nationalize"
+
+	keyName      = "name"
+	keyNameBatch = "name[]"
+	keyCountryID = "country_id"
+
+	headerRetryAfter         = "Retry-After"
+	headerRateLimitLimit     = "X-Rate-Limit-Limit"
+	headerRateLimitRemaining = "X-Rate-Limit-Remaining"
+	headerRateLimitReset     = "X-Rate-Limit-Reset"
+
+	maxBatchSize = 10
+)
+
+var countries = []string{"US", "GB", "DE", "FR", "RU", "UA", "PL", "IT", "ES", "BR", "IN", "JP", "CN", "TR", "KZ"}
+
+// Faults configures the failures injected into responses. Rates are the
+// probability, between 0 and 1, that a request fails that way.
+type Faults struct {
+	Latency       time.Duration
+	RateLimitRate float64
+	ErrorRate     float64
+	MalformedRate float64
+	// RetryAfter is sent with injected 429 and 5xx responses when set.
+	RetryAfter time.Duration
+}
+
+type Config struct {
+	// Faults apply to every API without an entry in APIFaults.
+	Faults    Faults
+	APIFaults map[string]Faults
+	// Quota limits the requests per API and QuotaWindow, and is reported in
+	// the X-Rate-Limit headers. Zero means unlimited.
+	Quota       int
+	QuotaWindow time.Duration
+	// Seed makes the sequence of injected faults repeatable.
+	Seed uint64
+}
+
+// URLs are the endpoints to configure as AGIFY_URL, GENDERIZE_URL and
+// NATIONALIZE_URL.
+type URLs struct {
+	Agify       string
+	Genderize   string
+	Nationalize string
+}
+
+// URLsFor returns the endpoints of a Server listening at baseURL.
+func URLsFor(baseURL string) URLs {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return URLs{
+		Agify:       baseURL + "/" + APIAgify,
+		Genderize:   baseURL + "/" + APIGenderize,
+		Nationalize: baseURL + "/" + APINationalize,
+	}
+}
+
+type quota struct {
+	used    int
+	resetAt time.Time
+}
+
+type Server struct {
+	mu       sync.Mutex
+	cfg      Config
+	rand     *rand.Rand
+	quotas   map[string]*quota
+	requests map[string]int
+}
+
+func New(cfg Config) *Server {
+	s := &Server{}
+	s.SetConfig(cfg)
+	return s
+}
+
+// SetConfig replaces the configuration and resets quotas and request counts.
+func (s *Server) SetConfig(cfg Config) {
+	if cfg.QuotaWindow <= 0 {
+		cfg.QuotaWindow = 24 * time.Hour
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cfg = cfg
+	s.rand = rand.New(rand.NewPCG(cfg.Seed, cfg.Seed))
+	s.quotas = make(map[string]*quota)
+	s.requests = make(map[string]int)
+}
+
+// Requests returns the number of requests the API has received.
+func (s *Server) Requests(api string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[api]
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api := strings.Trim(r.URL.Path, "/")
+	if api != APIAgify && api != APIGenderize && api != APINationalize {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	fault, faults, allowed := s.admit(api, w.Header())
+	if faults.Latency > 0 {
+		select {
+		case <-time.After(faults.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if !allowed {
+		writeError(w, http.StatusTooManyRequests, "Request limit reached")
+		return
+	}
+	switch fault {
+	case http.StatusTooManyRequests:
+		setRetryAfter(w.Header(), faults.RetryAfter)
+		writeError(w, http.StatusTooManyRequests, "Request limit reached")
+		return
+	case http.StatusInternalServerError:
+		setRetryAfter(w.Header(), faults.RetryAfter)
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	case http.StatusOK:
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name": `))
+		return
+	}
+
+	query := r.URL.Query()
+	countryID := strings.ToUpper(query.Get(keyCountryID))
+
+	if names, ok := query[keyNameBatch]; ok {
+		if len(names) > maxBatchSize {
+			writeError(w, http.StatusUnprocessableEntity, "Invalid 'name[]' parameter")
+			return
+		}
+		results := make([]any, 0, len(names))
+		for _, name := range names {
+			results = append(results, predict(api, name, countryID))
+		}
+		writeJSON(w, http.StatusOK, results)
+		return
+	}
+
+	name := query.Get(keyName)
+	if name == "" {
+		writeError(w, http.StatusUnprocessableEntity, "Missing 'name' parameter")
+		return
+	}
+	writeJSON(w, http.StatusOK, predict(api, name, countryID))
+}
+
+// admit counts the request against the quota of api, sets the rate limit
+// headers and draws the fault to inject: 429, 500, 200 for malformed JSON or
+// zero for none.
+func (s *Server) admit(api string, header http.Header) (int, Faults, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[api]++
+
+	faults, ok := s.cfg.APIFaults[api]
+	if !ok {
+		faults = s.cfg.Faults
+	}
+
+	allowed := true
+	if s.cfg.Quota > 0 {
+		now := time.Now()
+		q, ok := s.quotas[api]
+		if !ok || now.After(q.resetAt) {
+			q = &quota{resetAt: now.Add(s.cfg.QuotaWindow)}
+			s.quotas[api] = q
+		}
+		if q.used < s.cfg.Quota {
+			q.used++
+		} else {
+			allowed = false
+		}
+
+		reset := int(time.Until(q.resetAt).Seconds())
+		header.Set(headerRateLimitLimit, strconv.Itoa(s.cfg.Quota))
+		header.Set(headerRateLimitRemaining, strconv.Itoa(s.cfg.Quota-q.used))
+		header.Set(headerRateLimitReset, strconv.Itoa(reset))
+		if !allowed {
+			header.Set(headerRetryAfter, strconv.Itoa(reset))
+		}
+	}
+
+	roll := s.rand.Float64()
+	switch {
+	case roll < faults.RateLimitRate:
+		return http.StatusTooManyRequests, faults, allowed
+	case roll < faults.RateLimitRate+faults.ErrorRate:
+		return http.StatusInternalServerError, faults, allowed
+	case roll < faults.RateLimitRate+faults.ErrorRate+faults.MalformedRate:
+		return http.StatusOK, faults, allowed
+	}
+	return 0, faults, allowed
+}
+
+type ageResponse struct {
+	Count     int    `json:"count"`
+	Name      string `json:"name"`
+	Age       int    `json:"age"`
+	CountryID string `json:"country_id,omitempty"`
+}
+
+type genderResponse struct {
+	Count       int     `json:"count"`
+	Name        string  `json:"name"`
+	Gender      string  `json:"gender"`
+	Probability float64 `json:"probability"`
+	CountryID   string  `json:"country_id,omitempty"`
+}
+
+type nationalityResponse struct {
+	Count   int       `json:"count"`
+	Name    string    `json:"name"`
+	Country []country `json:"country"`
+}
+
+type country struct {
+	CountryID   string  `json:"country_id"`
+	Probability float64 `json:"probability"`
+}
+
+// predict derives the answer of api for name from a hash of the name and,
+// for agify and genderize, the country it was localized to.
+func predict(api, name, countryID string) any {
+	h := hash(name, countryID)
+	count := 100 + int(h%50000)
+
+	switch api {
+	case APIAgify:
+		return ageResponse{Count: count, Name: name, Age: 18 + int(h>>16%70), CountryID: countryID}
+	case APIGenderize:
+		gender := "male"
+		if h>>24&1 == 1 {
+			gender = "female"
+		}
+		probability := 0.5 + float64(h>>32%50)/100
+		return genderResponse{Count: count, Name: name, Gender: gender, Probability: probability, CountryID: countryID}
+	default:
+		h = hash(name, "")
+		start := int(h >> 16 % uint64(len(countries)))
+		step := 1 + int(h>>56%uint64(len(countries)-1))
+		share := 0.9
+		result := nationalityResponse{Count: count, Name: name}
+		for i := range 3 {
+			probability := share * (0.5 + float64(h>>(24+8*i)%40)/100)
+			share -= probability
+			result.Country = append(result.Country, country{
+				CountryID:   countries[(start+i*step)%len(countries)],
+				Probability: float64(int(probability*1000)) / 1000,
+			})
+		}
+		return result
+	}
+}
+
+func hash(name, countryID string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(strings.ToLower(name)))
+	if countryID != "" {
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(countryID))
+	}
+	return h.Sum64()
+}
+
+func setRetryAfter(header http.Header, wait time.Duration) {
+	if wait > 0 {
+		header.Set(headerRetryAfter, strconv.Itoa(int(wait.Seconds())))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,155 @@
+package fakeenrich
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, srv *httptest.Server, api string, query url.Values) (*http.Response, []byte) {
+	t.Helper()
+
+	resp, err := http.Get(srv.URL + "/" + api + "?" + query.Encode())
+	if err != nil {
+		t.Fatalf("GET %s: %v", api, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading %s response: %v", api, err)
+	}
+	return resp, body
+}
+
+func TestServerIsDeterministic(t *testing.T) {
+	srv := httptest.NewServer(New(Config{}))
+	defer srv.Close()
+
+	tests := []struct {
+		api   string
+		query url.Values
+	}{
+		{APIAgify, url.Values{keyName: {"Dmitriy"}}},
+		{APIGenderize, url.Values{keyName: {"Dmitriy"}, keyCountryID: {"ru"}}},
+		{APINationalize, url.Values{keyName: {"Dmitriy"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.api, func(t *testing.T) {
+			resp, first := get(t, srv, tt.api, tt.query)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+			}
+			if _, second := get(t, srv, tt.api, tt.query); string(second) != string(first) {
+				t.Errorf("second answer %s, want %s", second, first)
+			}
+		})
+	}
+}
+
+func TestServerBatch(t *testing.T) {
+	srv := httptest.NewServer(New(Config{}))
+	defer srv.Close()
+
+	resp, body := get(t, srv, APIAgify, url.Values{keyNameBatch: {"Anna", "Olga"}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var ages []ageResponse
+	if err := json.Unmarshal(body, &ages); err != nil {
+		t.Fatalf("batch response %s: %v", body, err)
+	}
+	single := predict(APIAgify, "Olga", "").(ageResponse)
+	if len(ages) != 2 || ages[0].Name != "Anna" || ages[1] != single {
+		t.Errorf("batch = %+v, want Anna then %+v", ages, single)
+	}
+
+	names := make([]string, maxBatchSize+1)
+	for i := range names {
+		names[i] = "name"
+	}
+	if resp, _ := get(t, srv, APIAgify, url.Values{keyNameBatch: names}); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("oversized batch status = %d, want %d", resp.StatusCode, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            Config
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{
+			name:           "rate limit",
+			cfg:            Config{Faults: Faults{RateLimitRate: 1, RetryAfter: 2 * time.Second}},
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "2",
+		},
+		{
+			name:       "server error",
+			cfg:        Config{Faults: Faults{ErrorRate: 1}},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "per api faults override",
+			cfg:        Config{Faults: Faults{ErrorRate: 1}, APIFaults: map[string]Faults{APIAgify: {}}},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := New(tt.cfg)
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+
+			resp, _ := get(t, srv, APIAgify, url.Values{keyName: {"Anna"}})
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get(headerRetryAfter); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+			if got := fake.Requests(APIAgify); got != 1 {
+				t.Errorf("Requests() = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestServerMalformed(t *testing.T) {
+	srv := httptest.NewServer(New(Config{Faults: Faults{MalformedRate: 1}}))
+	defer srv.Close()
+
+	resp, body := get(t, srv, APIGenderize, url.Values{keyName: {"Anna"}})
+	if resp.StatusCode != http.StatusOK || json.Valid(body) {
+		t.Errorf("got %d %s, want 200 with malformed JSON", resp.StatusCode, body)
+	}
+}
+
+func TestServerQuota(t *testing.T) {
+	srv := httptest.NewServer(New(Config{Quota: 1, QuotaWindow: time.Hour}))
+	defer srv.Close()
+
+	query := url.Values{keyName: {"Anna"}}
+	resp, _ := get(t, srv, APINationalize, query)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(headerRateLimitRemaining) != "0" {
+		t.Fatalf("first request: %d with %s remaining, want 200 with 0", resp.StatusCode, resp.Header.Get(headerRateLimitRemaining))
+	}
+
+	resp, _ = get(t, srv, APINationalize, query)
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get(headerRetryAfter) == "" {
+		t.Errorf("over quota: %d, Retry-After %q, want 429 with Retry-After", resp.StatusCode, resp.Header.Get(headerRetryAfter))
+	}
+
+	// The quota is per API.
+	if resp, _ := get(t, srv, APIAgify, query); resp.StatusCode != http.StatusOK {
+		t.Errorf("other api status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
@@ -0,0 +1,232 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func equalJSON(t *testing.T, got []byte, want string) bool {
+	t.Helper()
+
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("result is not JSON: %v", err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("want is not JSON: %v", err)
+	}
+	return reflect.DeepEqual(g, w)
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"replace member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"null removes member", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"objects merge", `{"a":{"b":"c","d":"e"}}`, `{"a":{"d":null,"f":"g"}}`, `{"a":{"b":"c","f":"g"}}`},
+		{"arrays are replaced", `{"a":[1,2]}`, `{"a":[3]}`, `{"a":[3]}`},
+		{"non-object patch replaces document", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"object patch over scalar", `"a"`, `{"b":"c"}`, `{"b":"c"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("MergePatch() error = %v", err)
+			}
+			if !equalJSON(t, got, tt.want) {
+				t.Errorf("MergePatch() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := MergePatch([]byte(`{}`), []byte(`{`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("malformed patch: error = %v, want %v", err, ErrInvalidPatch)
+	}
+}
+
+func TestPatchApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "add member",
+			doc:   `{"a":1}`,
+			patch: `[{"op":"add","path":"/b","value":2}]`,
+			want:  `{"a":1,"b":2}`,
+		},
+		{
+			name:  "add inserts into array",
+			doc:   `{"a":[1,3]}`,
+			patch: `[{"op":"add","path":"/a/1","value":2}]`,
+			want:  `{"a":[1,2,3]}`,
+		},
+		{
+			name:  "add appends with dash",
+			doc:   `{"a":[1]}`,
+			patch: `[{"op":"add","path":"/a/-","value":2}]`,
+			want:  `{"a":[1,2]}`,
+		},
+		{
+			name:  "add replaces whole document",
+			doc:   `{"a":1}`,
+			patch: `[{"op":"add","path":"","value":[1]}]`,
+			want:  `[1]`,
+		},
+		{
+			name:  "remove array element",
+			doc:   `{"a":[1,2,3]}`,
+			patch: `[{"op":"remove","path":"/a/1"}]`,
+			want:  `{"a":[1,3]}`,
+		},
+		{
+			name:  "replace member",
+			doc:   `{"a":{"b":1}}`,
+			patch: `[{"op":"replace","path":"/a/b","value":"x"}]`,
+			want:  `{"a":{"b":"x"}}`,
+		},
+		{
+			name:  "tilde one unescapes to slash",
+			doc:   `{"a/b":1}`,
+			patch: `[{"op":"replace","path":"/a~1b","value":2}]`,
+			want:  `{"a/b":2}`,
+		},
+		{
+			name:  "tilde zero unescapes to tilde",
+			doc:   `{"m~n":1}`,
+			patch: `[{"op":"remove","path":"/m~0n"}]`,
+			want:  `{}`,
+		},
+		{
+			name:  "tilde zero one is not a slash",
+			doc:   `{"~1":1,"/":2}`,
+			patch: `[{"op":"remove","path":"/~01"}]`,
+			want:  `{"/":2}`,
+		},
+		{
+			name:  "empty token names empty member",
+			doc:   `{"":1}`,
+			patch: `[{"op":"replace","path":"/","value":2}]`,
+			want:  `{"":2}`,
+		},
+		{
+			name:  "move member",
+			doc:   `{"a":{"b":1},"c":{}}`,
+			patch: `[{"op":"move","from":"/a/b","path":"/c/d"}]`,
+			want:  `{"a":{},"c":{"d":1}}`,
+		},
+		{
+			name:  "move array element",
+			doc:   `{"a":[1,2,3]}`,
+			patch: `[{"op":"move","from":"/a/0","path":"/a/-"}]`,
+			want:  `{"a":[2,3,1]}`,
+		},
+		{
+			name:    "move into itself",
+			doc:     `{"a":{"b":{}}}`,
+			patch:   `[{"op":"move","from":"/a","path":"/a/b/c"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:  "copy is deep",
+			doc:   `{"a":{"b":[1]}}`,
+			patch: `[{"op":"copy","from":"/a","path":"/c"},{"op":"add","path":"/c/b/-","value":2}]`,
+			want:  `{"a":{"b":[1]},"c":{"b":[1,2]}}`,
+		},
+		{
+			name:  "test passes",
+			doc:   `{"a":{"b":[1,"x"]}}`,
+			patch: `[{"op":"test","path":"/a","value":{"b":[1,"x"]}}]`,
+			want:  `{"a":{"b":[1,"x"]}}`,
+		},
+		{
+			name:    "test fails",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"test","path":"/a","value":"1"}]`,
+			wantErr: ErrTestFailed,
+		},
+		{
+			name:    "failed test discards earlier operations",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":1}]`,
+			wantErr: ErrTestFailed,
+		},
+		{
+			name:    "replace missing member",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"replace","path":"/b","value":2}]`,
+			wantErr: ErrPathNotFound,
+		},
+		{
+			name:    "remove past end of array",
+			doc:     `{"a":[1]}`,
+			patch:   `[{"op":"remove","path":"/a/1"}]`,
+			wantErr: ErrPathNotFound,
+		},
+		{
+			name:    "leading zero index",
+			doc:     `{"a":[1,2]}`,
+			patch:   `[{"op":"remove","path":"/a/01"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "path without slash",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"remove","path":"a"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "missing value",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"add","path":"/b"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "unknown operation",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"increment","path":"/a"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := Decode([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			got, err := patch.Apply([]byte(tt.doc))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Apply() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if !equalJSON(t, got, tt.want) {
+				t.Errorf("Apply() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	if _, err := Decode([]byte(`{"op":"add"}`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("Decode() error = %v, want %v", err, ErrInvalidPatch)
+	}
+}
@@ -1,6 +1,7 @@
 package migrations
 
 import (
+	_ "Effective/migrations"
 	"Effective/pkg/logger"
 	"context"
 	"os"
@@ -0,0 +1,261 @@
+package names
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrUnparsable is returned for a full name that cannot be split.
+var ErrUnparsable = errors.New("cannot split full name")
+
+// Orders in which the parts of a full name can be written: S is the surname,
+// N the first name and P the patronymic.
+const (
+	OrderSNP = "surname name patronymic"
+	OrderNPS = "name patronymic surname"
+	OrderNSP = "name surname patronymic"
+	OrderSPN = "surname patronymic name"
+	OrderPNS = "patronymic name surname"
+	OrderPSN = "patronymic surname name"
+	OrderNS  = "name surname"
+	OrderSN  = "surname name"
+)
+
+// FullName is a full name split into its parts. Confidence, between 0 and 1,
+// is how much more likely Order is than every other order.
+type FullName struct {
+	Name       string
+	Surname    string
+	Patronymic string
+	Order      string
+	Confidence float64
+}
+
+// surnameSuffixes are endings of East Slavic, Georgian and Armenian surnames,
+// in Latin.
+var surnameSuffixes = []string{
+	"ov", "ova", "ev", "eva", "in", "ina", "sky", "skii", "skiy", "skij", "skaya", "skaia",
+	"enko", "uk", "chuk", "yk", "ko", "dze", "shvili", "yan", "ian",
+}
+
+type role int
+
+const (
+	roleName role = iota
+	roleSurname
+	rolePatronymic
+)
+
+type order struct {
+	name  string
+	roles []role
+	prior float64
+	// surnameFirst marks orders that a comma after the first part, as in
+	// "Ivanov, Ivan", points to.
+	surnameFirst bool
+}
+
+// orders lists the candidate orders by part count. Priors favour the usual
+// Russian forms, surname first in official records and name first in speech.
+var orders = map[int][]order{
+	2: {
+		{name: OrderNS, roles: []role{roleName, roleSurname}, prior: 0.5},
+		{name: OrderSN, roles: []role{roleSurname, roleName}, surnameFirst: true},
+	},
+	3: {
+		{name: OrderSNP, roles: []role{roleSurname, roleName, rolePatronymic}, prior: 1, surnameFirst: true},
+		{name: OrderNPS, roles: []role{roleName, rolePatronymic, roleSurname}, prior: 0.5},
+		{name: OrderNSP, roles: []role{roleName, roleSurname, rolePatronymic}},
+		{name: OrderSPN, roles: []role{roleSurname, rolePatronymic, roleName}, prior: -1, surnameFirst: true},
+		{name: OrderPNS, roles: []role{rolePatronymic, roleName, roleSurname}, prior: -1},
+		{name: OrderPSN, roles: []role{rolePatronymic, roleSurname, roleName}, prior: -1},
+	},
+}
+
+//go:embed dict/*.txt
+var dictFS embed.FS
+
+var (
+	firstNames = loadDict("dict/first_names.txt")
+	surnames   = loadDict("dict/surnames.txt")
+)
+
+func loadDict(path string) map[string]bool {
+	f, err := dictFS.Open(path)
+	if err != nil {
+		panic(fmt.Sprintf("names: open %s: %v", path, err))
+	}
+	defer f.Close()
+
+	dict := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			dict[line] = true
+		}
+	}
+	return dict
+}
+
+// Parse splits a free-form full name of two or three parts, such as
+// "Ivanov Ivan Ivanovich", "Ivan I. Ivanov" or "Петров, Пётр", into first
+// name, surname and patronymic. Every possible order is scored against
+// patronymic suffixes, surname suffixes and the first-name and surname
+// dictionaries and the most likely one wins. An initial such as "I." can be
+// a first name or a patronymic and is returned as written.
+func Parse(fullName string) (FullName, error) {
+	fullName = Normalize(fullName)
+	comma := strings.Index(fullName, ",")
+	parts := strings.Fields(strings.ReplaceAll(fullName, ",", " "))
+
+	candidates, ok := orders[len(parts)]
+	if !ok {
+		return FullName{}, fmt.Errorf("%w: expected two or three parts, got %d", ErrUnparsable, len(parts))
+	}
+	for i, part := range parts {
+		if initial, ok := asInitial(part); ok {
+			parts[i] = initial
+			continue
+		}
+		if !Valid(part) {
+			return FullName{}, fmt.Errorf("%w: %q is not a name", ErrUnparsable, part)
+		}
+		parts[i] = Normalize(part)
+	}
+	surnameFirst := comma >= 0 && comma <= len(parts[0])+1
+
+	scores := make([]float64, len(candidates))
+	best := -1
+	for i, candidate := range candidates {
+		scores[i] = candidate.prior
+		if surnameFirst && candidate.surnameFirst {
+			scores[i] += 3
+		}
+		for j, part := range parts {
+			score, ok := scoreRole(part, candidate.roles[j])
+			if !ok {
+				scores[i] = math.Inf(-1)
+				break
+			}
+			scores[i] += score
+		}
+		if !math.IsInf(scores[i], -1) && (best < 0 || scores[i] > scores[best]) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return FullName{}, fmt.Errorf("%w: no order fits %q", ErrUnparsable, fullName)
+	}
+
+	var total float64
+	for _, score := range scores {
+		total += math.Exp(score - scores[best])
+	}
+
+	result := FullName{
+		Order:      candidates[best].name,
+		Confidence: math.Round(100/total) / 100,
+	}
+	for j, part := range parts {
+		switch candidates[best].roles[j] {
+		case roleName:
+			result.Name = part
+		case roleSurname:
+			result.Surname = part
+		case rolePatronymic:
+			result.Patronymic = part
+		}
+	}
+	return result, nil
+}
+
+// IsInitial reports whether part is an initial such as "I." or "И".
+func IsInitial(part string) bool {
+	_, ok := asInitial(part)
+	return ok
+}
+
+// asInitial returns part as a capital letter with a dot if it is an initial.
+func asInitial(part string) (string, bool) {
+	letter := strings.TrimSuffix(part, ".")
+	r, size := utf8.DecodeRuneInString(letter)
+	if size == 0 || size != len(letter) || !unicode.IsLetter(r) {
+		return "", false
+	}
+	return string(unicode.ToUpper(r)) + ".", true
+}
+
+// scoreRole scores how well part fits role; false rules the role out.
+func scoreRole(part string, r role) (float64, bool) {
+	if IsInitial(part) {
+		switch r {
+		case roleName, rolePatronymic:
+			return 1, true
+		default:
+			return 0, false
+		}
+	}
+
+	_, patronymicProbability, patronymic := PatronymicGender(part)
+	firstName := inDict(firstNames, part)
+	surname := inDict(surnames, part)
+	surnameSuffix := hasSurnameSuffix(part)
+
+	var score float64
+	switch r {
+	case roleName:
+		if firstName {
+			score += 3
+		}
+		if patronymic {
+			score -= 3 * patronymicProbability
+		}
+		if surname || surnameSuffix {
+			score--
+		}
+	case roleSurname:
+		if surname {
+			score += 3
+		}
+		if surnameSuffix {
+			score += 2
+		}
+		if firstName {
+			score--
+		}
+	case rolePatronymic:
+		if patronymic {
+			score += 4 * patronymicProbability
+		} else {
+			score -= 2
+		}
+		if firstName {
+			score--
+		}
+	}
+	return score, true
+}
+
+// inDict looks part up in dict by its ICAO and GOST spellings.
+func inDict(dict map[string]bool, part string) bool {
+	return dict[strings.ToLower(Transliterate(part, SchemeICAO))] || dict[strings.ToLower(Transliterate(part, SchemeGOST))]
+}
+
+func hasSurnameSuffix(part string) bool {
+	for _, scheme := range []string{SchemeICAO, SchemeGOST} {
+		latin := strings.ToLower(Transliterate(part, scheme))
+		for _, suffix := range surnameSuffixes {
+			if len(latin) > len(suffix)+1 && strings.HasSuffix(latin, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package names
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		fullName string
+		want     FullName
+	}{
+		{
+			name:     "surname name patronymic",
+			fullName: "Ivanov Ivan Ivanovich",
+			want:     FullName{Name: "Ivan", Surname: "Ivanov", Patronymic: "Ivanovich", Order: OrderSNP},
+		},
+		{
+			name:     "name patronymic surname in cyrillic",
+			fullName: "анна сергеевна петрова",
+			want:     FullName{Name: "Анна", Surname: "Петрова", Patronymic: "Сергеевна", Order: OrderNPS},
+		},
+		{
+			name:     "initial as patronymic",
+			fullName: "Ivan i. Ivanov",
+			want:     FullName{Name: "Ivan", Surname: "Ivanov", Patronymic: "I.", Order: OrderNPS},
+		},
+		{
+			name:     "name surname",
+			fullName: "Anna Petrova",
+			want:     FullName{Name: "Anna", Surname: "Petrova", Order: OrderNS},
+		},
+		{
+			name:     "comma puts the surname first",
+			fullName: "Петров, Пётр",
+			want:     FullName{Name: "Пётр", Surname: "Петров", Order: OrderSN},
+		},
+		{
+			name:     "short patronymic",
+			fullName: "Ульянов Владимир Ильич",
+			want:     FullName{Name: "Владимир", Surname: "Ульянов", Patronymic: "Ильич", Order: OrderSNP},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.fullName)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.fullName, err)
+			}
+			if got.Confidence <= 0 || got.Confidence > 1 {
+				t.Errorf("Parse(%q) confidence = %v, want in (0, 1]", tt.fullName, got.Confidence)
+			}
+			got.Confidence = 0
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.fullName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUnparsable(t *testing.T) {
+	for _, fullName := range []string{"", "Ivan", "Ivanov Ivan Ivanovich Jr", "Ivanov R2D2"} {
+		if _, err := Parse(fullName); !errors.Is(err, ErrUnparsable) {
+			t.Errorf("Parse(%q) error = %v, want %v", fullName, err, ErrUnparsable)
+		}
+	}
+}
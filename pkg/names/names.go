@@ -0,0 +1,76 @@
+// Package names validates, normalizes and transliterates personal names in
+// any script.
+package names
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize returns name in NFC with surrounding space trimmed and inner
+// runs of space collapsed. A name typed entirely in lower or upper case is
+// title-cased per part, so "иван" and "ПЕТРОВ-ВОДКИН" become "Иван" and
+// "Петров-Водкин"; mixed case such as "McDonald" is kept as typed.
+func Normalize(name string) string {
+	name = strings.Join(strings.Fields(norm.NFC.String(name)), " ")
+	if name == "" || (name != strings.ToLower(name) && name != strings.ToUpper(name)) {
+		return name
+	}
+
+	var b strings.Builder
+	b.Grow(len(name))
+	start := true
+	for _, r := range strings.ToLower(name) {
+		if start && unicode.IsLetter(r) {
+			b.WriteRune(unicode.ToTitle(r))
+			start = false
+			continue
+		}
+		b.WriteRune(r)
+		if isSeparator(r) {
+			start = true
+		}
+	}
+	return b.String()
+}
+
+// Valid reports whether name, once normalized, is made of letters in any
+// script, with combining marks, joined by single spaces, hyphens or
+// apostrophes: "Иван", "Петров-Водкин", "O'Brien" and "José" are valid,
+// "R2D2", "-Ivan" and "Ivan--Petrov" are not.
+func Valid(name string) bool {
+	name = Normalize(name)
+	if name == "" {
+		return false
+	}
+
+	prev := ' '
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r):
+		case unicode.Is(unicode.Mn, r):
+			if !unicode.IsLetter(prev) && !unicode.Is(unicode.Mn, prev) {
+				return false
+			}
+		case isSeparator(r):
+			if isSeparator(prev) || i+utf8.RuneLen(r) == len(name) {
+				return false
+			}
+		default:
+			return false
+		}
+		prev = r
+	}
+	return true
+}
+
+func isSeparator(r rune) bool {
+	switch r {
+	case ' ', '-', '\'', '’':
+		return true
+	}
+	return false
+}
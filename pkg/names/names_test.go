@@ -0,0 +1,58 @@
+package names
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"lower case is title-cased", "иван", "Иван"},
+		{"upper case is title-cased per part", "ПЕТРОВ-ВОДКИН", "Петров-Водкин"},
+		{"apostrophe starts a part", "o'brien", "O'Brien"},
+		{"mixed case is kept", "McDonald", "McDonald"},
+		{"space is trimmed and collapsed", "  anna   maria ", "Anna Maria"},
+		{"decomposed letters are composed", "Jose\u0301", "José"},
+		{"empty", "   ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"Иван", true},
+		{"Петров-Водкин", true},
+		{"O'Brien", true},
+		{"José", true},
+		{"Jose\u0301", true},
+		{"Ἀλέξανδρος", true},
+		{"Anna Maria", true},
+		{"", false},
+		{"R2D2", false},
+		{"-Ivan", false},
+		{"Ivan-", false},
+		{"Ivan--Petrov", false},
+		{"Ivan -Petrov", false},
+		{"\u0301Ivan", false},
+		{"Ivan!", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(tt.name); got != tt.want {
+				t.Errorf("Valid(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
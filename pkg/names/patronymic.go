@@ -0,0 +1,52 @@
+package names
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// patronymicSuffixes maps East Slavic and Turkic patronymic endings, in Latin
+// and Cyrillic, to the gender they imply and how likely the ending is to be a
+// patronymic at all. Longer endings come first. A bare "ich" is weak: it ends
+// short patronymics such as Ilyich and Lukich, but also given names such as
+// Dietrich and surnames such as Babich.
+var patronymicSuffixes = []struct {
+	suffix      string
+	gender      string
+	probability float64
+}{
+	{"ichna", "female", 0.99},
+	{"ична", "female", 0.99},
+	{"ovna", "female", 0.99},
+	{"evna", "female", 0.99},
+	{"овна", "female", 0.99},
+	{"евна", "female", 0.99},
+	{"kyzy", "female", 0.99},
+	{"kizi", "female", 0.99},
+	{"кызы", "female", 0.99},
+	{"ovich", "male", 0.99},
+	{"evich", "male", 0.99},
+	{"ович", "male", 0.99},
+	{"евич", "male", 0.99},
+	{"ich", "male", 0.6},
+	{"ич", "male", 0.6},
+	{"ogly", "male", 0.99},
+	{"oglu", "male", 0.99},
+	{"оглы", "male", 0.99},
+}
+
+// PatronymicGender returns the gender a patronymic implies, if any, and the
+// probability that its ending is a patronymic one.
+func PatronymicGender(patronymic string) (string, float64, bool) {
+	patronymic = strings.ToLower(strings.TrimSpace(patronymic))
+	if utf8.RuneCountInString(patronymic) < 4 {
+		return "", 0, false
+	}
+
+	for _, s := range patronymicSuffixes {
+		if strings.HasSuffix(patronymic, s.suffix) {
+			return s.gender, s.probability, true
+		}
+	}
+	return "", 0, false
+}
@@ -0,0 +1,34 @@
+package names
+
+import "testing"
+
+func TestPatronymicGender(t *testing.T) {
+	tests := []struct {
+		patronymic      string
+		wantGender      string
+		wantProbability float64
+		wantOK          bool
+	}{
+		{"Ivanovich", "male", 0.99, true},
+		{"Иванович", "male", 0.99, true},
+		{"Sergeevna", "female", 0.99, true},
+		{"ИЛЬИНИЧНА", "female", 0.99, true},
+		{"Mamed ogly", "male", 0.99, true},
+		{"Aliyev kyzy", "female", 0.99, true},
+		{"Ильич", "male", 0.6, true},
+		{"Dietrich", "male", 0.6, true},
+		{"Ivanov", "", 0, false},
+		{"Ich", "", 0, false},
+		{"", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.patronymic, func(t *testing.T) {
+			gender, probability, ok := PatronymicGender(tt.patronymic)
+			if gender != tt.wantGender || probability != tt.wantProbability || ok != tt.wantOK {
+				t.Errorf("PatronymicGender(%q) = %q, %v, %v, want %q, %v, %v",
+					tt.patronymic, gender, probability, ok, tt.wantGender, tt.wantProbability, tt.wantOK)
+			}
+		})
+	}
+}
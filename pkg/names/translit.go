@@ -0,0 +1,98 @@
+package names
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Transliteration schemes for Cyrillic. ICAO follows Doc 9303, as used in
+// machine-readable passports; GOST follows GOST 7.79-2000 system B without
+// its apostrophes and backticks, which the name APIs do not understand.
+const (
+	SchemeICAO = "icao"
+	SchemeGOST = "gost"
+)
+
+var icao = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "ie", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu",
+	'я': "ia", 'і': "i", 'ї': "i", 'є': "ie", 'ґ': "g", 'ў': "u",
+}
+
+var gost = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "j", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "x", 'ц': "cz",
+	'ч': "ch", 'ш': "sh", 'щ': "shh", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+}
+
+// greek maps Greek letters after ELOT 743 and is used by every scheme.
+var greek = map[rune]string{
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th",
+	'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p",
+	'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps",
+	'ω': "o",
+}
+
+// latin spells out Latin letters that do not decompose into a base letter
+// and combining marks.
+var latin = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th",
+	'ı': "i", '’': "'",
+}
+
+// Transliterate spells name in plain ASCII Latin letters with scheme, which
+// defaults to ICAO. Cyrillic and Greek letters are mapped by table,
+// diacritics are dropped from Latin letters and letters of other scripts are
+// kept as they are. A capital letter stays capitalized.
+func Transliterate(name, scheme string) string {
+	table := icao
+	if scheme == SchemeGOST {
+		table = gost
+	}
+
+	runes := []rune(norm.NFC.String(name))
+	var b strings.Builder
+	b.Grow(len(name))
+	for i, r := range runes {
+		lower := unicode.ToLower(r)
+		s, ok := table[lower]
+		if !ok {
+			s, ok = latin[lower]
+		}
+		if ok {
+			if lower == 'ц' && scheme == SchemeGOST && i+1 < len(runes) && strings.ContainsRune("еиыйіє", unicode.ToLower(runes[i+1])) {
+				s = "c"
+			}
+			b.WriteString(matchCase(s, r))
+			continue
+		}
+
+		// Drop the diacritics of anything else, so accented Greek and
+		// Latin letters are spelled by their base letter.
+		for _, d := range norm.NFD.String(string(r)) {
+			if unicode.Is(unicode.Mn, d) {
+				continue
+			}
+			if s, ok := greek[unicode.ToLower(d)]; ok {
+				b.WriteString(matchCase(s, d))
+				continue
+			}
+			b.WriteRune(d)
+		}
+	}
+	return b.String()
+}
+
+// matchCase capitalizes s when r is a capital letter.
+func matchCase(s string, r rune) string {
+	if s == "" || !unicode.IsUpper(r) {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
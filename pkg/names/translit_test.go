@@ -0,0 +1,40 @@
+package names
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		scheme string
+		want   string
+	}{
+		{"icao", "Щербаков", SchemeICAO, "Shcherbakov"},
+		{"gost", "Щербаков", SchemeGOST, "Shherbakov"},
+		{"icao is the default", "Юлия", "", "Iuliia"},
+		{"gost short i and ya", "Юлия", SchemeGOST, "Yuliya"},
+		{"icao kh", "Харитонов", SchemeICAO, "Kharitonov"},
+		{"gost x", "Харитонов", SchemeGOST, "Xaritonov"},
+		{"icao yo", "Пётр", SchemeICAO, "Petr"},
+		{"gost yo", "Пётр", SchemeGOST, "Pyotr"},
+		{"icao ts", "Цой", SchemeICAO, "Tsoi"},
+		{"gost c before front vowels", "Цицерон", SchemeGOST, "Ciceron"},
+		{"gost cz elsewhere", "Цой", SchemeGOST, "Czoj"},
+		{"gost cz at the end", "Кузнец", SchemeGOST, "Kuznecz"},
+		{"gost c before capital vowel", "ЦИЦЕРОН", SchemeGOST, "CICERON"},
+		{"ukrainian yi", "Їжакевич", SchemeICAO, "Izhakevich"},
+		{"soft sign is dropped", "Игорь", SchemeICAO, "Igor"},
+		{"greek", "Αλέξανδρος", SchemeICAO, "Alexandros"},
+		{"latin diacritics", "José Müller", SchemeGOST, "Jose Muller"},
+		{"latin ligatures", "Søren Straße", SchemeICAO, "Soren Strasse"},
+		{"other scripts are kept", "李", SchemeICAO, "李"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Transliterate(tt.in, tt.scheme); got != tt.want {
+				t.Errorf("Transliterate(%q, %q) = %q, want %q", tt.in, tt.scheme, got, tt.want)
+			}
+		})
+	}
+}